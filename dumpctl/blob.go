@@ -0,0 +1,171 @@
+package dumpctl
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/png"
+	"math/rand"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/pingcap/tidb/types"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+// blobPlaceholders are the smallest valid files of each format: a blank 1x1
+// image or an empty page.
+var blobPlaceholders = map[string]func() []byte{
+	"png": func() []byte {
+		var buf bytes.Buffer
+		png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1)))
+		return buf.Bytes()
+	},
+	"gif": func() []byte {
+		var buf bytes.Buffer
+		gif.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1)), nil)
+		return buf.Bytes()
+	},
+	"pdf": pdfPlaceholder,
+}
+
+// pdfPlaceholder writes a PDF document with a single empty page.
+func pdfPlaceholder() []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+	}
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+// BlobRule replaces binary values with random bytes, a placeholder file or
+// an empty value.
+type BlobRule struct {
+	Columns     []string `cty:"columns"`
+	Mode        string   `cty:"mode"`
+	Length      *int     `cty:"length"`
+	Placeholder string   `cty:"placeholder"`
+	Contents    []byte
+}
+
+var blobRuleDefaultSpec = hcldec.ObjectSpec{
+	"columns": ColumnSpec,
+	"mode": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "mode",
+			Type: cty.String,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.StringVal("random")},
+	},
+	"length": &hcldec.AttrSpec{
+		Name: "length",
+		Type: cty.Number,
+	},
+	"placeholder": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "placeholder",
+			Type: cty.String,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.StringVal("png")},
+	},
+}
+
+// Replace returns the replacement for a binary value of length n.
+func (r *BlobRule) Replace(n int) types.BinaryLiteral {
+	switch r.Mode {
+	case "placeholder":
+		return types.BinaryLiteral(r.Contents)
+	case "empty":
+		return types.BinaryLiteral{}
+	}
+	if r.Length != nil {
+		n = *r.Length
+	}
+	b := make([]byte, n)
+	rand.Read(b)
+	return types.BinaryLiteral(b)
+}
+
+func (r *BlobRule) Apply(row *Row) error {
+	for _, columnName := range r.Columns {
+		column, ok := row.Table.Columns[columnName]
+		if !ok {
+			continue
+		}
+
+		datum, ok := row.Datum(column)
+		if !ok || datum.Kind() == types.KindNull {
+			continue
+		}
+		var n int
+		switch datum.Kind() {
+		case types.KindBinaryLiteral, types.KindMysqlBit, types.KindBytes:
+			n = len(datum.GetBytes())
+		case types.KindString:
+			n = len(datum.GetString())
+		default:
+			return fmt.Errorf("cannot replace column %s with kind %d", column.Name, datum.Kind())
+		}
+		row.SetValue(column, r.Replace(n))
+	}
+	return nil
+}
+
+func (r *BlobRule) CheckColumn(column *Column) error {
+	if !column.IsBinary() {
+		return fmt.Errorf("only binary and blob columns can be replaced")
+	}
+	return nil
+}
+
+func init() {
+	RegisterRule("blob", blobRuleDefaultSpec, func(value cty.Value, block *hcl.Block, table *Table) (Rule, error) {
+		return NewBlobRule(value)
+	})
+}
+
+func NewBlobRule(value cty.Value) (*BlobRule, error) {
+	rule := &BlobRule{}
+	err := gocty.FromCtyValue(value, &rule)
+	if err == nil {
+		err = rule.validate()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *BlobRule) validate() error {
+	switch r.Mode {
+	case "random", "empty":
+	case "placeholder":
+		placeholder, ok := blobPlaceholders[r.Placeholder]
+		if !ok {
+			return fmt.Errorf("placeholder must be one of png, gif or pdf")
+		}
+		r.Contents = placeholder()
+	default:
+		return fmt.Errorf("mode must be one of random, placeholder or empty")
+	}
+	if r.Length != nil && *r.Length < 0 {
+		return fmt.Errorf("length must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package dumpctl
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/pingcap/tidb/types"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+type BucketRule struct {
+	Columns    []string  `cty:"columns"`
+	Size       *float64  `cty:"size"`
+	Boundaries []float64 `cty:"boundaries"`
+	Prefix     *int      `cty:"prefix"`
+	Fill       *string   `cty:"fill"`
+	Precision  *string   `cty:"precision"`
+	// size and boundaries are Size and Boundaries as exact decimals.
+	size       *big.Rat
+	boundaries []*big.Rat
+}
+
+var bucketRuleDefaultSpec = hcldec.ObjectSpec{
+	"columns": ColumnSpec,
+	"size": &hcldec.AttrSpec{
+		Name: "size",
+		Type: cty.Number,
+	},
+	"boundaries": &hcldec.AttrSpec{
+		Name: "boundaries",
+		Type: cty.List(cty.Number),
+	},
+	"prefix": &hcldec.AttrSpec{
+		Name: "prefix",
+		Type: cty.Number,
+	},
+	"fill": &hcldec.AttrSpec{
+		Name: "fill",
+		Type: cty.String,
+	},
+	"precision": &hcldec.AttrSpec{
+		Name: "precision",
+		Type: cty.String,
+	},
+}
+
+// decimalRat returns the shortest decimal that f is written as, so that a
+// configured size of 0.1 is exactly one tenth.
+func decimalRat(f float64) *big.Rat {
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	return r
+}
+
+// numericValue converts value to a value that can be written to a numeric
+// column. Decimals keep the number of decimal places of the original datum.
+func numericValue(column *Column, original types.Datum, value *big.Rat) (interface{}, error) {
+	switch {
+	case column.IsInteger():
+		if min, max := column.NumericRange(); value.Cmp(min) < 0 || value.Cmp(max) > 0 {
+			return nil, fmt.Errorf("%s is out of range for column %s", value.RatString(), column.Name)
+		}
+		n := new(big.Int).Div(value.Num(), value.Denom())
+		if n.Sign() < 0 {
+			return n.Int64(), nil
+		}
+		return n.Uint64(), nil
+	case original.Kind() == types.KindMysqlDecimal:
+		_, frac := original.GetMysqlDecimal().PrecisionAndFrac()
+		dec := new(types.MyDecimal)
+		err := dec.FromString([]byte(value.FloatString(frac)))
+		return dec, err
+	default:
+		f, _ := value.Float64()
+		return f, nil
+	}
+}
+
+// Bucket generalizes a single value according to the rule.
+func (r *BucketRule) Bucket(column *Column, datum types.Datum) (interface{}, error) {
+	switch {
+	case column.IsNumeric() || column.Type == "year":
+		if r.Size == nil && len(r.Boundaries) == 0 {
+			return nil, fmt.Errorf("bucket rule needs size or boundaries for numeric column %s", column.Name)
+		}
+		s, err := datum.ToString()
+		if err != nil {
+			return nil, err
+		}
+		value, ok := new(big.Rat).SetString(s)
+		if !ok {
+			return nil, fmt.Errorf("cannot bucket %s in column %s", s, column.Name)
+		}
+		bucket, err := r.bucketNumber(value)
+		if err != nil {
+			return nil, fmt.Errorf("cannot bucket column %s: %w", column.Name, err)
+		}
+		return numericValue(column, datum, bucket)
+	case column.IsTemporal():
+		if r.Precision == nil {
+			return nil, fmt.Errorf("bucket rule needs precision for temporal column %s", column.Name)
+		}
+		s, err := datum.ToString()
+		if err != nil {
+			return nil, err
+		}
+		return r.bucketTemporal(s)
+	default:
+		if r.Prefix == nil {
+			return nil, fmt.Errorf("bucket rule needs prefix for column %s", column.Name)
+		}
+		s, err := datum.ToString()
+		if err != nil {
+			return nil, err
+		}
+		return column.Truncate(r.bucketString(s)), nil
+	}
+}
+
+// bucketNumber returns the bucket of value. Values below the smallest of the
+// boundaries have no bucket and are an error.
+func (r *BucketRule) bucketNumber(value *big.Rat) (*big.Rat, error) {
+	if len(r.boundaries) > 0 {
+		i := sort.Search(len(r.boundaries), func(i int) bool {
+			return r.boundaries[i].Cmp(value) > 0
+		})
+		if i == 0 {
+			return nil, fmt.Errorf("%s is below the smallest boundary %s, add a lower boundary for it", value.RatString(), r.boundaries[0].RatString())
+		}
+		return r.boundaries[i-1], nil
+	}
+	quotient := new(big.Rat).Quo(value, r.size)
+	n := new(big.Int).Div(quotient.Num(), quotient.Denom())
+	return new(big.Rat).Mul(new(big.Rat).SetInt(n), r.size), nil
+}
+
+func (r *BucketRule) bucketTemporal(s string) (string, error) {
+	value, err := parseTemporal(s)
+	if err != nil {
+		return "", err
+	}
+	if !value.HasDate || value.IsZero() {
+		return s, nil
+	}
+	value.Truncate(*r.Precision)
+	return value.String(), nil
+}
+
+func (r *BucketRule) bucketString(s string) string {
+	runes := []rune(s)
+	if len(runes) <= *r.Prefix {
+		return s
+	}
+	if r.Fill == nil {
+		return string(runes[:*r.Prefix])
+	}
+	return string(runes[:*r.Prefix]) + strings.Repeat(*r.Fill, len(runes)-*r.Prefix)
+}
+
+func (r *BucketRule) Apply(row *Row) error {
+	for _, columnName := range r.Columns {
+		column, ok := row.Table.Columns[columnName]
+		if !ok {
+			continue
+		}
+
+		datum, ok := row.Datum(column)
+		if !ok || datum.Kind() == types.KindNull {
+			continue
+		}
+		value, err := r.Bucket(column, datum)
+		if err != nil {
+			return err
+		}
+		row.SetValue(column, value)
+	}
+	return nil
+}
+
+func (r *BucketRule) CheckColumn(column *Column) error {
+	switch {
+	case column.IsNumeric() || column.Type == "year":
+		if r.Size == nil && len(r.Boundaries) == 0 {
+			return fmt.Errorf("numeric columns need size or boundaries")
+		}
+	case column.Type == "time":
+		return fmt.Errorf("time columns have no date to truncate")
+	case column.IsTemporal():
+		if r.Precision == nil {
+			return fmt.Errorf("temporal columns need precision")
+		}
+	case column.IsText():
+		if r.Prefix == nil {
+			return fmt.Errorf("character columns need prefix")
+		}
+	default:
+		return fmt.Errorf("%s columns cannot be bucketed", column.Type)
+	}
+	return nil
+}
+
+func init() {
+	RegisterRule("bucket", bucketRuleDefaultSpec, func(value cty.Value, block *hcl.Block, table *Table) (Rule, error) {
+		return NewBucketRule(value)
+	})
+}
+
+func NewBucketRule(value cty.Value) (*BucketRule, error) {
+	rule := &BucketRule{}
+	err := gocty.FromCtyValue(value, &rule)
+	if err == nil {
+		err = rule.validate()
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.Float64s(rule.Boundaries)
+	if rule.Size != nil {
+		rule.size = decimalRat(*rule.Size)
+	}
+	for _, boundary := range rule.Boundaries {
+		rule.boundaries = append(rule.boundaries, decimalRat(boundary))
+	}
+	return rule, nil
+}
+
+func (r *BucketRule) validate() error {
+	if r.Size == nil && len(r.Boundaries) == 0 && r.Prefix == nil && r.Precision == nil {
+		return fmt.Errorf("one of size, boundaries, prefix or precision is required")
+	}
+	if r.Size != nil && *r.Size <= 0 {
+		return fmt.Errorf("size must be greater than 0")
+	}
+	if r.Prefix != nil && *r.Prefix < 0 {
+		return fmt.Errorf("prefix must not be negative")
+	}
+	if r.Precision != nil {
+		switch *r.Precision {
+		case "day", "month", "year":
+		default:
+			return fmt.Errorf("precision must be one of day, month or year")
+		}
+	}
+	return nil
+}
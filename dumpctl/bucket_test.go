@@ -0,0 +1,68 @@
+package dumpctl
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/pingcap/tidb/types"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestBucketNumeric(t *testing.T) {
+	bigint := &Column{Name: "n", Type: "bigint", ColumnType: "bigint unsigned"}
+	decimal := &Column{Name: "d", Type: "decimal", ColumnType: "decimal(65,2)", Precision: sql.NullInt64{Int64: 65, Valid: true}, Scale: sql.NullInt64{Int64: 2, Valid: true}}
+	mustDecimal := func(s string) types.Datum {
+		dec := new(types.MyDecimal)
+		if err := dec.FromString([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+		return types.NewDecimalDatum(dec)
+	}
+	tests := []struct {
+		size       cty.Value
+		boundaries cty.Value
+		column     *Column
+		datum      types.Datum
+		want       string
+	}{
+		{cty.NumberIntVal(10), cty.NullVal(cty.List(cty.Number)), bigint, types.NewUintDatum(18446744073709551615), "18446744073709551610"},
+		{cty.NumberIntVal(10), cty.NullVal(cty.List(cty.Number)), bigint, types.NewUintDatum(9007199254740993), "9007199254740990"},
+		{cty.NumberFloatVal(0.1), cty.NullVal(cty.List(cty.Number)), decimal, mustDecimal("0.30"), "0.30"},
+		{cty.NumberIntVal(1000), cty.NullVal(cty.List(cty.Number)), decimal, mustDecimal("123456789012345678901234567890.99"), "123456789012345678901234567000.00"},
+		{cty.NullVal(cty.Number), cty.ListVal([]cty.Value{cty.NumberIntVal(0), cty.NumberIntVal(18), cty.NumberIntVal(65)}), bigint, types.NewUintDatum(65), "65"},
+		{cty.NullVal(cty.Number), cty.ListVal([]cty.Value{cty.NumberIntVal(65), cty.NumberIntVal(0), cty.NumberIntVal(18)}), bigint, types.NewUintDatum(40), "18"},
+	}
+	for _, test := range tests {
+		rule, err := NewBucketRule(cty.ObjectVal(map[string]cty.Value{
+			"columns":    cty.ListVal([]cty.Value{cty.StringVal(test.column.Name)}),
+			"size":       test.size,
+			"boundaries": test.boundaries,
+			"prefix":     cty.NullVal(cty.Number),
+			"fill":       cty.NullVal(cty.String),
+			"precision":  cty.NullVal(cty.String),
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, err := rule.Bucket(test.column, test.datum)
+		if err != nil {
+			t.Errorf("bucketing %v: %v", test.datum.GetValue(), err)
+			continue
+		}
+		if got := fmt.Sprint(value); got != test.want {
+			t.Errorf("bucketing %v = %s, want %s", test.datum.GetValue(), got, test.want)
+		}
+	}
+}
+
+func TestBucketCheckColumn(t *testing.T) {
+	precision := "day"
+	rule := &BucketRule{Precision: &precision}
+	if err := rule.CheckColumn(&Column{Name: "at", Type: "datetime", ColumnType: "datetime"}); err != nil {
+		t.Errorf("datetime column: %v", err)
+	}
+	if err := rule.CheckColumn(&Column{Name: "at", Type: "time", ColumnType: "time"}); err == nil {
+		t.Error("time column was accepted")
+	}
+}
@@ -0,0 +1,69 @@
+package dumpctl
+
+import (
+	"log"
+	"os"
+
+	"github.com/jessevdk/go-flags"
+)
+
+var opts Options
+
+type DecryptCommand struct{}
+
+var decryptCommand DecryptCommand
+
+// Main runs the dumpctl command line. Programs that register their own rule
+// types call it from their main function.
+func Main() {
+	log.SetFlags(0)
+	parser := flags.NewParser(&opts, flags.PassDoubleDash)
+	parser.SubcommandsOptional = true
+	parser.AddCommand(
+		"decrypt",
+		"Decrypt a dump",
+		"Reads a dump written with the same config from stdin and writes it to stdout with the values of fpe rules decrypted.",
+		&decryptCommand,
+	)
+	extraArgs, err := parser.Parse()
+
+	if opts.Help {
+		parser.WriteHelp(os.Stderr)
+		os.Exit(0)
+	}
+
+	if err != nil {
+		log.Printf("Error: %s\n\n", err.Error())
+		parser.WriteHelp(os.Stderr)
+		os.Exit(1)
+	}
+	opts.ExtraArgs = extraArgs
+
+	log.Printf("DEBUG: reading config")
+
+	if parser.Active != nil && parser.Active.Name == "decrypt" {
+		config, err := NewDecryptConfig(&opts)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		log.Printf("DEBUG: starting decrypt")
+		err = NewDecrypter(config).Decrypt(os.Stdin, os.Stdout)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+
+	config, err := NewConfig(&opts)
+
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	sequencer := NewDumpSequencer(config)
+	log.Printf("DEBUG: starting dump")
+	err = sequencer.Dump()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+}
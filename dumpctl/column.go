@@ -0,0 +1,237 @@
+package dumpctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pingcap/tidb/types"
+)
+
+func (c *Column) IsInteger() bool {
+	switch c.Type {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint":
+		return true
+	}
+	return false
+}
+
+func (c *Column) IsNumeric() bool {
+	switch c.Type {
+	case "decimal", "numeric", "float", "double", "real":
+		return true
+	}
+	return c.IsInteger()
+}
+
+func (c *Column) IsTemporal() bool {
+	switch c.Type {
+	case "date", "datetime", "timestamp", "time", "year":
+		return true
+	}
+	return false
+}
+
+func (c *Column) IsBinary() bool {
+	switch c.Type {
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob":
+		return true
+	}
+	return false
+}
+
+func (c *Column) IsText() bool {
+	switch c.Type {
+	case "char", "varchar", "tinytext", "text", "mediumtext", "longtext":
+		return true
+	}
+	return false
+}
+
+func (c *Column) IsUnsigned() bool {
+	return strings.Contains(c.ColumnType, "unsigned")
+}
+
+// IsUnique reports whether the column is the primary key or has a unique
+// index of its own.
+func (c *Column) IsUnique() bool {
+	return c.Key == "PRI" || c.Key == "UNI"
+}
+
+// IntegerRange returns the smallest and largest values an integer column can
+// hold.
+func (c *Column) IntegerRange() (min int64, max uint64) {
+	var bits uint
+	switch c.Type {
+	case "tinyint":
+		bits = 8
+	case "smallint":
+		bits = 16
+	case "mediumint":
+		bits = 24
+	case "int", "integer":
+		bits = 32
+	default:
+		bits = 64
+	}
+	if c.IsUnsigned() {
+		if bits == 64 {
+			return 0, math.MaxUint64
+		}
+		return 0, 1<<bits - 1
+	}
+	return -1 << (bits - 1), 1<<(bits-1) - 1
+}
+
+// NumericRange returns the smallest and largest values a numeric column can
+// hold. Decimal columns are limited by their precision and scale.
+func (c *Column) NumericRange() (min, max *big.Rat) {
+	switch {
+	case c.IsInteger():
+		low, high := c.IntegerRange()
+		min = new(big.Rat).SetInt64(low)
+		max = new(big.Rat).SetInt(new(big.Int).SetUint64(high))
+	case c.Type == "float":
+		max = new(big.Rat).SetFloat64(math.MaxFloat32)
+	case (c.Type == "decimal" || c.Type == "numeric") && c.Precision.Valid && c.Scale.Valid:
+		digits := new(big.Int).Exp(big.NewInt(10), big.NewInt(c.Precision.Int64), nil)
+		max = new(big.Rat).SetFrac(digits.Sub(digits, big.NewInt(1)), new(big.Int).Exp(big.NewInt(10), big.NewInt(c.Scale.Int64), nil))
+	default:
+		max = new(big.Rat).SetFloat64(math.MaxFloat64)
+	}
+	if min == nil {
+		min = new(big.Rat).Neg(max)
+		if c.IsUnsigned() {
+			min = new(big.Rat)
+		}
+	}
+	return min, max
+}
+
+// Truncate shortens s to the column's CHARACTER_MAXIMUM_LENGTH, if it has one.
+func (c *Column) Truncate(s string) string {
+	if !c.MaxLength.Valid {
+		return s
+	}
+	runes := []rune(s)
+	if int64(len(runes)) <= c.MaxLength.Int64 {
+		return s
+	}
+	return string(runes[:c.MaxLength.Int64])
+}
+
+// Elements returns the members of an ENUM or SET column as declared in its
+// COLUMN_TYPE, e.g. enum('a','b').
+func (c *Column) Elements() []string {
+	start, end := strings.IndexByte(c.ColumnType, '('), strings.LastIndexByte(c.ColumnType, ')')
+	if start < 0 || end < start {
+		return nil
+	}
+	var elements []string
+	var sb strings.Builder
+	quoted := false
+	list := c.ColumnType[start+1 : end]
+	for i := 0; i < len(list); i++ {
+		switch ch := list[i]; {
+		case ch == '\'' && quoted && i+1 < len(list) && list[i+1] == '\'':
+			sb.WriteByte('\'')
+			i++
+		case ch == '\'':
+			if quoted {
+				elements = append(elements, sb.String())
+				sb.Reset()
+			}
+			quoted = !quoted
+		case quoted:
+			sb.WriteByte(ch)
+		}
+	}
+	return elements
+}
+
+func (c *Column) hasElement(s string) bool {
+	for _, element := range c.Elements() {
+		if strings.EqualFold(element, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate reports values that MySQL would reject or change when importing
+// them into the column in strict mode.
+func (c *Column) Validate(datum types.Datum) error {
+	if datum.Kind() == types.KindNull {
+		if !c.Nullable {
+			return fmt.Errorf("NULL is not allowed in a NOT NULL column")
+		}
+		return nil
+	}
+	if c.Type == "bit" {
+		return nil
+	}
+	var s string
+	if datum.Kind() == types.KindBinaryLiteral || datum.Kind() == types.KindMysqlBit {
+		s = string(datum.GetBytes())
+	} else {
+		var err error
+		if s, err = datum.ToString(); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case c.IsNumeric():
+		value, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+		if !ok {
+			return fmt.Errorf("%q is not a number", s)
+		}
+		min, max := c.NumericRange()
+		if value.Cmp(min) < 0 || value.Cmp(max) > 0 {
+			return fmt.Errorf("%s is out of range for %s", s, c.ColumnType)
+		}
+	case c.Type == "year":
+		year, err := strconv.Atoi(s)
+		if err != nil || year < 0 || (year > 99 && year < 1901) || year > 2155 {
+			return fmt.Errorf("%q is not a valid year", s)
+		}
+	case c.IsTemporal():
+		value, err := parseTemporal(s)
+		if err != nil {
+			return err
+		}
+		if err := value.Validate(c.Type); err != nil {
+			return err
+		}
+	case c.IsText():
+		if length := utf8.RuneCountInString(s); c.MaxLength.Valid && int64(length) > c.MaxLength.Int64 {
+			return fmt.Errorf("%d characters are longer than the maximum of %d", length, c.MaxLength.Int64)
+		}
+	case c.IsBinary():
+		if c.MaxLength.Valid && int64(len(s)) > c.MaxLength.Int64 {
+			return fmt.Errorf("%d bytes are longer than the maximum of %d", len(s), c.MaxLength.Int64)
+		}
+	case c.Type == "enum":
+		if !c.hasElement(s) {
+			return fmt.Errorf("%q is not a member of %s", s, c.ColumnType)
+		}
+	case c.Type == "set":
+		if len(s) == 0 {
+			return nil
+		}
+		for _, member := range strings.Split(s, ",") {
+			if !c.hasElement(member) {
+				return fmt.Errorf("%q is not a member of %s", member, c.ColumnType)
+			}
+		}
+	case c.Type == "json":
+		if !json.Valid([]byte(s)) {
+			return fmt.Errorf("%q is not valid JSON", s)
+		}
+	}
+	return nil
+}
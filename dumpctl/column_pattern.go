@@ -0,0 +1,216 @@
+package dumpctl
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+// ColumnPattern applies rules to the columns of every table of a database
+// whose names match a pattern. Patterns containing % are SQL LIKE patterns,
+// all others are globs. Both are matched regardless of case.
+type ColumnPattern struct {
+	Pattern string
+	// Types limits the pattern to columns with one of these data types.
+	Types []string
+	Rules hcl.Blocks
+	Block *hcl.Block
+	match func(string) bool
+}
+
+var columnPatternSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "type"},
+	},
+	Blocks: []hcl.BlockHeaderSchema{
+		{
+			Type:       "rule",
+			LabelNames: []string{"name"},
+		},
+	},
+}
+
+// likeRegexp converts a SQL LIKE pattern to a regular expression.
+func likeRegexp(pattern string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, c := range pattern {
+		switch c {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
+
+func NewColumnPattern(pattern string, block *hcl.Block) (columnPattern *ColumnPattern, diags hcl.Diagnostics) {
+	content, diags := block.Body.Content(columnPatternSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	columnPattern = &ColumnPattern{
+		Pattern: pattern,
+		Rules:   content.Blocks.OfType("rule"),
+		Block:   block,
+	}
+
+	lower := strings.ToLower(pattern)
+	if strings.Contains(lower, "%") {
+		like := likeRegexp(lower)
+		columnPattern.match = like.MatchString
+	} else {
+		if _, err := path.Match(lower, ""); err != nil {
+			return nil, diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("invalid column pattern %s: %v", pattern, err),
+				Subject:  &block.LabelRanges[0],
+			})
+		}
+		columnPattern.match = func(name string) bool {
+			ok, _ := path.Match(lower, name)
+			return ok
+		}
+	}
+
+	if attr, ok := content.Attributes["type"]; ok {
+		value, moreDiags := attr.Expr.Value(nil)
+		if diags = append(diags, moreDiags...); moreDiags.HasErrors() {
+			return nil, diags
+		}
+		if value.Type() == cty.String {
+			value = cty.ListVal([]cty.Value{value})
+		}
+		value, err := convert.Convert(value, cty.List(cty.String))
+		if err == nil {
+			err = gocty.FromCtyValue(value, &columnPattern.Types)
+		}
+		if err != nil {
+			return nil, diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("type must be a data type or a list of data types: %v", err),
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+		}
+	}
+	return
+}
+
+// Match reports whether a column's name, and its type if the pattern has
+// types, match the pattern.
+func (p *ColumnPattern) Match(column *Column) bool {
+	if len(p.Types) > 0 {
+		found := false
+		for _, t := range p.Types {
+			found = found || strings.EqualFold(t, column.Type)
+		}
+		if !found {
+			return false
+		}
+	}
+	return p.match(strings.ToLower(column.Name))
+}
+
+// Columns returns the names of the columns of a table that match the pattern,
+// in the order of the table.
+func (p *ColumnPattern) Columns(t *Table) []string {
+	var columns []*Column
+	for _, column := range t.Columns {
+		if p.Match(column) {
+			columns = append(columns, column)
+		}
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		return columns[i].Position < columns[j].Position
+	})
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.Name
+	}
+	return names
+}
+
+// columnsBody adds a `columns` attribute to the body of a rule block, so that
+// the rules of a column pattern apply to the columns that match it.
+type columnsBody struct {
+	hcl.Body
+	Columns *hcl.Attribute
+}
+
+func (b *columnsBody) withoutColumns(schema *hcl.BodySchema) (*hcl.BodySchema, bool) {
+	result := &hcl.BodySchema{Blocks: schema.Blocks}
+	found := false
+	for _, attr := range schema.Attributes {
+		if attr.Name == b.Columns.Name {
+			found = true
+			continue
+		}
+		result.Attributes = append(result.Attributes, attr)
+	}
+	return result, found
+}
+
+func (b *columnsBody) addColumns(content *hcl.BodyContent, found bool) *hcl.BodyContent {
+	if found {
+		attrs := make(hcl.Attributes, len(content.Attributes)+1)
+		for name, attr := range content.Attributes {
+			attrs[name] = attr
+		}
+		attrs[b.Columns.Name] = b.Columns
+		content.Attributes = attrs
+	}
+	return content
+}
+
+func (b *columnsBody) Content(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Diagnostics) {
+	schema, found := b.withoutColumns(schema)
+	content, diags := b.Body.Content(schema)
+	return b.addColumns(content, found), diags
+}
+
+func (b *columnsBody) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Body, hcl.Diagnostics) {
+	schema, found := b.withoutColumns(schema)
+	content, remain, diags := b.Body.PartialContent(schema)
+	if found {
+		return b.addColumns(content, found), remain, diags
+	}
+	return content, &columnsBody{Body: remain, Columns: b.Columns}, diags
+}
+
+// AddColumnPatternRules adds the rules of a column pattern to the table for
+// the columns that match it.
+func (t *Table) AddColumnPatternRules(pattern *ColumnPattern) (diags hcl.Diagnostics) {
+	columns := pattern.Columns(t)
+	if len(columns) == 0 {
+		return
+	}
+	values := make([]cty.Value, len(columns))
+	for i, name := range columns {
+		values[i] = cty.StringVal(name)
+	}
+	labelRange := pattern.Block.LabelRanges[0]
+	attr := &hcl.Attribute{
+		Name:      "columns",
+		Expr:      hcl.StaticExpr(cty.ListVal(values), labelRange),
+		Range:     labelRange,
+		NameRange: labelRange,
+	}
+	for _, ruleBlock := range pattern.Rules {
+		block := *ruleBlock
+		block.Body = &columnsBody{Body: ruleBlock.Body, Columns: attr}
+		_, moreDiags := t.AddRule(block.Labels[0], &block)
+		diags = append(diags, moreDiags...)
+	}
+	return
+}
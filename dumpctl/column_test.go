@@ -0,0 +1,26 @@
+package dumpctl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestColumnElements(t *testing.T) {
+	tests := []struct {
+		columnType string
+		want       []string
+	}{
+		{"enum('a','b')", []string{"a", "b"}},
+		{"set('read','write','admin')", []string{"read", "write", "admin"}},
+		{"enum('it''s','a,b','(x)')", []string{"it's", "a,b", "(x)"}},
+		{"enum('')", []string{""}},
+		{"varchar(255)", nil},
+		{"text", nil},
+	}
+	for _, test := range tests {
+		column := &Column{ColumnType: test.columnType}
+		if got := column.Elements(); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Elements of %s = %q, want %q", test.columnType, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package dumpctl
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// ConditionalRule applies another rule only to the rows for which its `when`
+// expression is true.
+type ConditionalRule struct {
+	Rule Rule
+	When hcl.Expression
+}
+
+func NewConditionalRule(rule Rule, when *hcl.Attribute, table *Table) (*ConditionalRule, hcl.Diagnostics) {
+	diags := checkRowReferences(when.Expr, table)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return &ConditionalRule{Rule: rule, When: when.Expr}, diags
+}
+
+// checkRowReferences reports references in expr to anything other than the
+// columns of the table through the `row` variable. A bare column name is an
+// error rather than a reference to its value, which would otherwise evaluate
+// to the name of the column.
+func checkRowReferences(expr hcl.Expression, table *Table) (diags hcl.Diagnostics) {
+	for _, traversal := range expr.Variables() {
+		if root := traversal.RootName(); root != "row" {
+			if _, ok := table.Columns[root]; ok {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  fmt.Sprintf("%s is a column name, not the value of the column", root),
+					Detail:   fmt.Sprintf("Use row.%s to refer to the value of %s in the current row.", root, root),
+					Subject:  traversal.SourceRange().Ptr(),
+				})
+			} else {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  fmt.Sprintf("there is no variable named %s", root),
+					Detail:   "The values of the current row are available as attributes of row.",
+					Subject:  traversal.SourceRange().Ptr(),
+				})
+			}
+			continue
+		}
+		if len(traversal) < 2 {
+			continue
+		}
+		var name string
+		switch step := traversal[1].(type) {
+		case hcl.TraverseAttr:
+			name = step.Name
+		case hcl.TraverseIndex:
+			if step.Key.Type() == cty.String {
+				name = step.Key.AsString()
+			}
+		}
+		if _, ok := table.Columns[name]; len(name) > 0 && !ok {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("%s has no column named %s", table, name),
+				Subject:  traversal.SourceRange().Ptr(),
+			})
+		}
+	}
+	return
+}
+
+func (r *ConditionalRule) Apply(row *Row) error {
+	value, diags := r.When.Value(row.EvalContext())
+	if diags.HasErrors() {
+		return diags
+	}
+	value, err := convert.Convert(value, cty.Bool)
+	if err != nil {
+		return fmt.Errorf("when expression must be a boolean: %w", err)
+	}
+	if value.IsNull() || value.False() {
+		return nil
+	}
+	return r.Rule.Apply(row)
+}
@@ -0,0 +1,219 @@
+package dumpctl
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/pingcap/tidb/parser/ast"
+)
+
+// Options are the command line options of dumpctl.
+type Options struct {
+	ConfigFile string `short:"c" long:"config" description:"Path to config file" required:"true"`
+	Host       string `short:"h" long:"host" description:"hostname of server" default:"127.0.0.1"`
+	Port       string `short:"P" long:"port" description:"port of server" default:"3306"`
+	Socket     string `short:"S" long:"socket"`
+	User       string `short:"u" long:"user" description:"user for login"`
+	Password   string `short:"p" long:"password" description:"password for login"`
+	Binpath    string `long:"binpath" description:"Path to mysqldump" default:"mysqldump"`
+	Help       bool   `long:"help" description:"Display this (help) message"`
+	Verbose    []bool `short:"v" long:"verbose" description:"Show verbose debug information"`
+	ExtraArgs  []string
+}
+
+type Config struct {
+	Databases map[string]*Database
+	Policies  map[string]*Policy
+	Options   *Options
+	File      *hcl.File
+	Started   time.Time
+	Conn      *sql.DB
+	// Decrypting is set when the config is read to decrypt a dump. Schemas
+	// are then read from the dump instead of the server and only reversible
+	// rules are configured.
+	Decrypting bool
+	// ExecProcesses are the commands of exec rules by their arguments.
+	ExecProcesses map[string]*ExecProcess
+}
+
+var configSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{
+			Type:       "database",
+			LabelNames: []string{"name"},
+		},
+		{
+			Type:       "policy",
+			LabelNames: []string{"name"},
+		},
+	},
+}
+
+type Column struct {
+	Name       string
+	Position   int64
+	Type       string
+	ColumnType string
+	MaxLength  sql.NullInt64
+	Nullable   bool
+	Default    sql.NullString
+	Precision  sql.NullInt64
+	Scale      sql.NullInt64
+	Key        string
+	Table      *Table
+}
+
+func (c *Column) String() string {
+	return fmt.Sprintf(c.Table.Name, c.Name)
+}
+
+type Row struct {
+	Table  *Table
+	Values *[]ast.ExprNode
+	// Number is the position of the row in the dump of its table, starting at
+	// 1.
+	Number int64
+	// Suppressed rows are left out of the dump.
+	Suppressed bool
+}
+
+func NewConfig(opts *Options) (config *Config, err error) {
+	conn, err := NewConnection(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return readConfig(opts, conn)
+}
+
+// NewDecryptConfig reads the config to decrypt a dump. It does not connect to
+// the server, the schemas of tables are read from the dump by the Decrypter.
+func NewDecryptConfig(opts *Options) (*Config, error) {
+	return readConfig(opts, nil)
+}
+
+func readConfig(opts *Options, conn *sql.DB) (config *Config, err error) {
+	parser := hclparse.NewParser()
+	f, diags := parser.ParseHCLFile(opts.ConfigFile)
+
+	config = &Config{
+		Databases:  make(map[string]*Database),
+		Policies:   make(map[string]*Policy),
+		Options:    opts,
+		File:       f,
+		Started:    time.Now(),
+		Conn:       conn,
+		Decrypting: conn == nil,
+	}
+
+	moreDiags := config.Read()
+	diags = append(diags, moreDiags...)
+
+	var sb strings.Builder
+	wr := hcl.NewDiagnosticTextWriter(&sb, parser.Files(), 78, true)
+	wr.WriteDiagnostics(diags)
+	if diags.HasErrors() {
+		err = fmt.Errorf("Could not read config")
+	}
+	log.Println(sb.String())
+
+	return
+}
+
+func NewConnection(opts *Options) (*sql.DB, error) {
+	var dsn string
+	if len(opts.Socket) != 0 {
+		dsn = fmt.Sprintf(
+			"%s:%s@unix(%s)/",
+			opts.User,
+			opts.Password,
+			opts.Socket,
+		)
+	} else {
+		dsn = fmt.Sprintf(
+			"%s:%s@tcp(%s:%s)/",
+			opts.User,
+			opts.Password,
+			opts.Host,
+			opts.Port,
+		)
+	}
+	return sql.Open("mysql", dsn)
+}
+
+func (c *Config) Read() (diags hcl.Diagnostics) {
+	configContent, diags := c.File.Body.Content(configSchema)
+	if diags.HasErrors() {
+		return
+	}
+	// policies are read first so that tables can refer to them
+	for _, policyBlock := range configContent.Blocks.OfType("policy") {
+		name := policyBlock.Labels[0]
+		if _, ok := c.Policies[name]; ok {
+			diags = diags.Append(&hcl.Diagnostic{
+				Summary:  fmt.Sprintf("cannot add duplicate policy '%s'", name),
+				Subject:  &policyBlock.LabelRanges[0],
+				Severity: hcl.DiagError,
+			})
+			continue
+		}
+		policy, moreDiags := NewPolicy(name, policyBlock)
+		if diags = append(diags, moreDiags...); moreDiags.HasErrors() {
+			continue
+		}
+		c.Policies[name] = policy
+	}
+	for _, dbBlock := range configContent.Blocks.OfType("database") {
+		name := dbBlock.Labels[0]
+		database, moreDiags := NewDatabase(name, dbBlock, c)
+		c.Databases[name] = database
+		if diags = append(diags, moreDiags...); moreDiags.HasErrors() || c.Decrypting {
+			continue
+		}
+		moreDiags = database.ReadSchema()
+		if diags = append(diags, moreDiags...); moreDiags.HasErrors() {
+			continue
+		}
+		moreDiags = database.ReadDynamicConfig()
+		if diags = append(diags, moreDiags...); moreDiags.HasErrors() {
+			continue
+		}
+	}
+
+	return
+}
+
+// Path resolves a path from the config file relative to the directory the
+// config file is in.
+func (c *Config) Path(path string) string {
+	if filepath.IsAbs(path) || c.Options == nil {
+		return path
+	}
+	return filepath.Join(filepath.Dir(c.Options.ConfigFile), path)
+}
+
+// Close releases the resources held by the rules of every table.
+func (c *Config) Close() (err error) {
+	for _, database := range c.Databases {
+		for _, table := range database.Tables {
+			for _, closer := range table.Closers {
+				if closeErr := closer.Close(); err == nil {
+					err = closeErr
+				}
+			}
+		}
+	}
+	for _, process := range c.ExecProcesses {
+		if closeErr := process.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return
+}
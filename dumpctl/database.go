@@ -1,4 +1,4 @@
-package main
+package dumpctl
 
 import (
 	"fmt"
@@ -17,6 +17,8 @@ type Database struct {
 	Config      *Config
 	Destination string   `hcl:"destination_database,optional"`
 	Remain      hcl.Body `hcl:",remain"`
+	// ColumnPatterns apply rules to the matching columns of every table.
+	ColumnPatterns []*ColumnPattern
 }
 
 var databaseSchema = &hcl.BodySchema{
@@ -25,6 +27,10 @@ var databaseSchema = &hcl.BodySchema{
 			Type:       "table",
 			LabelNames: []string{"name"},
 		},
+		{
+			Type:       "columns",
+			LabelNames: []string{"pattern"},
+		},
 	},
 }
 
@@ -54,7 +60,14 @@ func NewDatabase(name string, block *hcl.Block, config *Config) (database *Datab
 		return
 	}
 
-	for _, tableBlock := range content.Blocks {
+	for _, patternBlock := range content.Blocks.OfType("columns") {
+		pattern, moreDiags := NewColumnPattern(patternBlock.Labels[0], patternBlock)
+		if diags = append(diags, moreDiags...); moreDiags.HasErrors() {
+			continue
+		}
+		database.ColumnPatterns = append(database.ColumnPatterns, pattern)
+	}
+	for _, tableBlock := range content.Blocks.OfType("table") {
 		_, moreDiags := database.AddTable(tableBlock.Labels[0], tableBlock)
 		if diags = append(diags, moreDiags...); moreDiags.HasErrors() {
 			continue
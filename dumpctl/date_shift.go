@@ -0,0 +1,160 @@
+package dumpctl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/pingcap/tidb/types"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+type DateShiftRule struct {
+	Columns   []string `cty:"columns"`
+	KeyColumn string   `cty:"key_column"`
+	MaxDays   int      `cty:"max_days"`
+	KeyEnv    string   `cty:"key_env"`
+	KeyFile   *string  `cty:"key_file"`
+	Key       []byte
+}
+
+var dateShiftRuleDefaultSpec = hcldec.ObjectSpec{
+	"columns": ColumnSpec,
+	"key_column": &hcldec.AttrSpec{
+		Name:     "key_column",
+		Type:     cty.String,
+		Required: true,
+	},
+	"max_days": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "max_days",
+			Type: cty.Number,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.NumberIntVal(365)},
+	},
+	"key_env": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "key_env",
+			Type: cty.String,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.StringVal("DUMPCTL_DATE_SHIFT_KEY")},
+	},
+	"key_file": &hcldec.AttrSpec{
+		Name: "key_file",
+		Type: cty.String,
+	},
+}
+
+// offset returns the number of days every date of the entity identified by
+// key is shifted by. It is derived from a keyed digest of the key, so it
+// cannot be recomputed without the rule's key. It is never zero.
+func (r *DateShiftRule) offset(key string) int {
+	n := binary.BigEndian.Uint64(token(r.Key, key)[:8])
+	days := int(n%uint64(2*r.MaxDays)) - r.MaxDays
+	if days >= 0 {
+		days++
+	}
+	return days
+}
+
+var (
+	minDatetime = time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)
+	maxDatetime = time.Date(9999, 12, 31, 23, 59, 59, 999999999, time.UTC)
+)
+
+// clampShifted limits a shifted value to the range of its column, so that
+// values near the ends of the range are not shifted out of it.
+func clampShifted(column *Column, tm time.Time) time.Time {
+	min, max := minDatetime, maxDatetime
+	if column.Type == "timestamp" {
+		min, max = minTimestamp, maxTimestamp
+	}
+	switch {
+	case tm.Before(min):
+		return min
+	case tm.After(max):
+		return max
+	}
+	return tm
+}
+
+func (r *DateShiftRule) Apply(row *Row) error {
+	keyColumn, ok := row.Table.Columns[r.KeyColumn]
+	if !ok {
+		return fmt.Errorf("date_shift key column %s does not exist in %s", r.KeyColumn, row.Table)
+	}
+	key, ok := row.Datum(keyColumn)
+	if !ok {
+		return nil
+	}
+	keyString, err := key.ToString()
+	if err != nil {
+		return err
+	}
+	days := r.offset(keyString)
+
+	for _, columnName := range r.Columns {
+		column, ok := row.Table.Columns[columnName]
+		if !ok {
+			continue
+		}
+
+		datum, ok := row.Datum(column)
+		if !ok || datum.Kind() == types.KindNull {
+			continue
+		}
+		s, err := datum.ToString()
+		if err != nil {
+			return err
+		}
+		value, err := parseTemporal(s)
+		if err != nil {
+			return fmt.Errorf("cannot shift column %s: %w", column.Name, err)
+		}
+		if !value.HasDate || value.IsZero() {
+			continue
+		}
+		value.SetTime(clampShifted(column, value.Time().AddDate(0, 0, days)))
+		row.SetValue(column, value.String())
+	}
+	return nil
+}
+
+func (r *DateShiftRule) CheckColumn(column *Column) error {
+	switch column.Type {
+	case "date", "datetime", "timestamp":
+		return nil
+	}
+	return fmt.Errorf("only date, datetime and timestamp columns can be shifted")
+}
+
+func init() {
+	RegisterRule("date_shift", dateShiftRuleDefaultSpec, func(value cty.Value, block *hcl.Block, table *Table) (Rule, error) {
+		rule, err := NewDateShiftRule(value, table.Database.Config)
+		if err != nil {
+			return nil, err
+		}
+		if diags := table.checkColumnAttribute(block.Body, "key_column", rule.KeyColumn); diags.HasErrors() {
+			return nil, diags
+		}
+		return rule, nil
+	})
+}
+
+func NewDateShiftRule(value cty.Value, config *Config) (*DateShiftRule, error) {
+	rule := &DateShiftRule{}
+	err := gocty.FromCtyValue(value, &rule)
+	if err == nil && rule.MaxDays < 1 {
+		err = fmt.Errorf("max_days must be at least 1")
+	}
+	if err == nil {
+		rule.Key, err = readKey(config, rule.KeyEnv, rule.KeyFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
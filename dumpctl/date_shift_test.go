@@ -0,0 +1,49 @@
+package dumpctl
+
+import (
+	"testing"
+
+	"github.com/pingcap/tidb/parser/ast"
+)
+
+func TestDateShiftRange(t *testing.T) {
+	table := &Table{Name: "events", Columns: map[string]*Column{}}
+	for i, column := range []*Column{
+		{Name: "id", Type: "int", ColumnType: "int"},
+		{Name: "created_at", Type: "timestamp", ColumnType: "timestamp"},
+		{Name: "starts_at", Type: "datetime", ColumnType: "datetime"},
+	} {
+		column.Position = int64(i + 1)
+		column.Table = table
+		table.Columns[column.Name] = column
+	}
+	rule := &DateShiftRule{Columns: []string{"created_at", "starts_at"}, KeyColumn: "id", MaxDays: 365, Key: []byte("secret")}
+
+	for _, limits := range [][]string{
+		{"1970-01-01 00:00:01", "0001-01-01 00:00:00"},
+		{"2038-01-19 03:14:07", "9999-12-31 23:59:59"},
+	} {
+		for id := 1; id <= 20; id++ {
+			values := []ast.ExprNode{
+				ast.NewValueExpr(int64(id), "", ""),
+				ast.NewValueExpr(limits[0], "", ""),
+				ast.NewValueExpr(limits[1], "", ""),
+			}
+			row := &Row{Table: table, Values: &values}
+			if err := rule.Apply(row); err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range rule.Columns {
+				column := table.Columns[name]
+				datum, _ := row.Datum(column)
+				value, err := parseTemporal(datum.GetString())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := value.Validate(column.Type); err != nil {
+					t.Errorf("shifting %s with key %d: %v", name, id, err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package dumpctl
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/format"
+	"github.com/pingcap/tidb/parser/mysql"
+	parsertypes "github.com/pingcap/tidb/parser/types"
+)
+
+var useStatement = regexp.MustCompile("^USE `([^`]+)`;$")
+
+// Decrypter reverses the reversible rules of a config in a dump that was
+// written with the same config. It does not connect to the server: the
+// columns of each table are read from its CREATE TABLE statement in the dump,
+// so only the keys of the reversible rules are needed.
+type Decrypter struct {
+	Config   *Config
+	Parser   *parser.Parser
+	Database *Database
+	// createTable collects the lines of a CREATE TABLE statement until it
+	// ends.
+	createTable []string
+}
+
+func NewDecrypter(config *Config) *Decrypter {
+	return &Decrypter{
+		Config: config,
+		Parser: parser.New(),
+	}
+}
+
+func (d *Decrypter) useDatabase(destination string) {
+	d.Database = nil
+	for _, database := range d.Config.Databases {
+		if database.Destination == destination {
+			d.Database = database
+			return
+		}
+	}
+}
+
+// readCreateTable reads the columns of a table from its CREATE TABLE
+// statement and configures the rules of the table for them.
+func (d *Decrypter) readCreateTable(sql string) error {
+	stmtNode, err := d.Parser.ParseOneStmt(sql, "", "")
+	if err != nil {
+		return err
+	}
+	stmt, ok := stmtNode.(*ast.CreateTableStmt)
+	if !ok {
+		return fmt.Errorf("could not parse CREATE TABLE statement")
+	}
+	if d.Database == nil {
+		return nil
+	}
+	table, ok := d.Database.Tables[stmt.Table.Name.O]
+	if !ok {
+		return nil
+	}
+	table.Columns = createTableColumns(table, stmt)
+	table.Rules = nil
+	if diags := table.ReadRules(); diags.HasErrors() {
+		return fmt.Errorf("could not configure the rules of %s: %s", table, diags.Error())
+	}
+	return nil
+}
+
+// createTableColumns returns the columns of a CREATE TABLE statement with the
+// attributes ReadSchema reads from INFORMATION_SCHEMA.COLUMNS.
+func createTableColumns(table *Table, stmt *ast.CreateTableStmt) map[string]*Column {
+	keys := make(map[string]string)
+	for _, constraint := range stmt.Constraints {
+		if len(constraint.Keys) != 1 || constraint.Keys[0].Column == nil {
+			continue
+		}
+		name := constraint.Keys[0].Column.Name.O
+		switch constraint.Tp {
+		case ast.ConstraintPrimaryKey:
+			keys[name] = "PRI"
+		case ast.ConstraintUniq, ast.ConstraintUniqKey, ast.ConstraintUniqIndex:
+			if keys[name] == "" {
+				keys[name] = "UNI"
+			}
+		}
+	}
+
+	columns := make(map[string]*Column, len(stmt.Cols))
+	for i, def := range stmt.Cols {
+		tp := def.Tp
+		column := &Column{
+			Name:       def.Name.Name.O,
+			Position:   int64(i + 1),
+			Type:       parsertypes.TypeToStr(tp.GetType(), tp.GetCharset()),
+			ColumnType: tp.InfoSchemaStr(),
+			Nullable:   true,
+			Key:        keys[def.Name.Name.O],
+			Table:      table,
+		}
+		if (parsertypes.IsTypeChar(tp.GetType()) || parsertypes.IsTypeBlob(tp.GetType())) && tp.GetFlen() > 0 {
+			column.MaxLength = sql.NullInt64{Int64: int64(tp.GetFlen()), Valid: true}
+		}
+		if tp.GetType() == mysql.TypeNewDecimal && tp.GetFlen() > 0 {
+			column.Precision = sql.NullInt64{Int64: int64(tp.GetFlen()), Valid: true}
+			column.Scale = sql.NullInt64{Int64: int64(tp.GetDecimal()), Valid: true}
+		}
+		for _, option := range def.Options {
+			switch option.Tp {
+			case ast.ColumnOptionNotNull, ast.ColumnOptionPrimaryKey:
+				column.Nullable = false
+			}
+			switch option.Tp {
+			case ast.ColumnOptionPrimaryKey:
+				column.Key = "PRI"
+			case ast.ColumnOptionUniqKey:
+				if column.Key == "" {
+					column.Key = "UNI"
+				}
+			}
+		}
+		if column.Key == "PRI" {
+			column.Nullable = false
+		}
+		columns[column.Name] = column
+	}
+	return columns
+}
+
+func (d *Decrypter) decryptInsert(line string, w io.Writer) error {
+	stmtNode, err := d.Parser.ParseOneStmt(line, "", "")
+	if err != nil {
+		return err
+	}
+	stmt, ok := stmtNode.(*ast.InsertStmt)
+	if !ok {
+		return fmt.Errorf("could not parse INSERT statement")
+	}
+	var table *Table
+	if source, ok := stmt.Table.TableRefs.Left.(*ast.TableSource); ok && d.Database != nil {
+		if name, ok := source.Source.(*ast.TableName); ok {
+			table = d.Database.Tables[name.Name.O]
+		}
+	}
+	// the rules of a table are configured once its CREATE TABLE statement
+	// has been read
+	if table != nil && len(table.Columns) > 0 {
+		for i := range stmt.Lists {
+			row := &Row{
+				Table:  table,
+				Values: &stmt.Lists[i],
+			}
+			for j := len(table.Rules) - 1; j >= 0; j-- {
+				if rule, ok := table.Rules[j].(ReversibleRule); ok {
+					if err := rule.Reverse(row); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	err = stmt.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, w))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write([]byte(";\n"))
+	return err
+}
+
+// Decrypt copies a dump from r to w, decrypting the values of INSERT
+// statements for the tables the config knows about. The dump must contain the
+// CREATE TABLE statements of these tables.
+func (d *Decrypter) Decrypt(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if match := useStatement.FindStringSubmatch(line); match != nil {
+			d.useDatabase(match[1])
+		}
+		if strings.HasPrefix(line, "CREATE TABLE") || len(d.createTable) > 0 {
+			d.createTable = append(d.createTable, line)
+			if strings.HasSuffix(line, ";") {
+				err := d.readCreateTable(strings.Join(d.createTable, "\n"))
+				d.createTable = nil
+				if err != nil {
+					return err
+				}
+			}
+		}
+		if len(line) > 5 && line[0:6] == "INSERT" {
+			if err := d.decryptInsert(line, w); err != nil {
+				return err
+			}
+		} else if _, err := fmt.Fprintf(w, "%s\n", line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -1,4 +1,4 @@
-package main
+package dumpctl
 
 import (
 	"fmt"
@@ -25,10 +25,11 @@ func NewDumpSequencer(config *Config) *DumpSequencer {
 func (s *DumpSequencer) Dump() error {
 	for _, database := range s.Config.Databases {
 		if err := s.DumpDatabase(database); err != nil {
+			s.Config.Close()
 			return err
 		}
 	}
-	return nil
+	return s.Config.Close()
 }
 
 func (s *DumpSequencer) DumpDatabase(database *Database) error {
@@ -0,0 +1,203 @@
+package dumpctl
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/pingcap/tidb/types"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+// EmailRule pseudonymizes email addresses, replacing the local part and the
+// domain separately.
+type EmailRule struct {
+	Columns     []string `cty:"columns"`
+	Local       string   `cty:"local"`
+	Domain      string   `cty:"domain"`
+	Domains     []string `cty:"domains"`
+	KeepDomains []string `cty:"keep_domains"`
+	KeyEnv      string   `cty:"key_env"`
+	KeyFile     *string  `cty:"key_file"`
+	Key         []byte
+	// Seen holds the addresses written to each unique column so far.
+	Seen map[string]map[string]bool
+}
+
+var emailRuleDefaultSpec = hcldec.ObjectSpec{
+	"columns": ColumnSpec,
+	"local": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "local",
+			Type: cty.String,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.StringVal("faker")},
+	},
+	"domain": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "domain",
+			Type: cty.String,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.StringVal("example.com")},
+	},
+	"domains": &hcldec.AttrSpec{
+		Name: "domains",
+		Type: cty.List(cty.String),
+	},
+	"keep_domains": &hcldec.AttrSpec{
+		Name: "keep_domains",
+		Type: cty.List(cty.String),
+	},
+	"key_env": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "key_env",
+			Type: cty.String,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.StringVal("DUMPCTL_TOKEN_KEY")},
+	},
+	"key_file": &hcldec.AttrSpec{
+		Name: "key_file",
+		Type: cty.String,
+	},
+}
+
+// localPart replaces the local part of an address. Replacements are derived
+// from the whole address, ignoring case, so an address is always replaced
+// the same way.
+func (r *EmailRule) localPart(local, address string) string {
+	switch r.Local {
+	case "keep":
+		return local
+	case "tokenize":
+		return hex.EncodeToString(token(r.Key, address))[:16]
+	default:
+		return fakerGenerators["username"](seededRand("email", address), "en")
+	}
+}
+
+func (r *EmailRule) domain(domain string) string {
+	for _, keep := range r.KeepDomains {
+		if strings.EqualFold(keep, domain) {
+			return domain
+		}
+	}
+	if len(r.Domains) > 0 {
+		return pick(seededRand("email", strings.ToLower(domain)), r.Domains)
+	}
+	return r.Domain
+}
+
+// address joins the parts of an address with the suffix +n, if n is not 0,
+// shortening the local part so that the address fits in the column.
+func address(column *Column, local, domain string, n int) string {
+	rest := "@" + domain
+	if n > 0 {
+		rest = "+" + strconv.Itoa(n) + rest
+	}
+	if column.MaxLength.Valid {
+		room := int(column.MaxLength.Int64) - utf8.RuneCountInString(rest)
+		if runes := []rune(local); room > 0 && len(runes) > room {
+			local = string(runes[:room])
+		}
+	}
+	return column.Truncate(local + rest)
+}
+
+// Pseudonymize replaces an address. In unique columns, addresses that were
+// already written receive a +n suffix so that the dump can still be
+// imported. It fails if the column is too short to hold the suffix.
+func (r *EmailRule) Pseudonymize(column *Column, s string) (string, error) {
+	lower := strings.ToLower(s)
+	local, domain := s, ""
+	if i := strings.LastIndexByte(s, '@'); i >= 0 {
+		local, domain = s[:i], s[i+1:]
+	}
+	local, domain = r.localPart(local, lower), r.domain(domain)
+	if !column.IsUnique() {
+		return address(column, local, domain, 0), nil
+	}
+
+	if r.Seen == nil {
+		r.Seen = make(map[string]map[string]bool)
+	}
+	seen, ok := r.Seen[column.Name]
+	if !ok {
+		seen = make(map[string]bool)
+		r.Seen[column.Name] = seen
+	}
+	previous := ""
+	for n := 0; ; n++ {
+		value := address(column, local, domain, n)
+		if !seen[strings.ToLower(value)] {
+			seen[strings.ToLower(value)] = true
+			return value, nil
+		}
+		if n > 0 && value == previous {
+			return "", fmt.Errorf("cannot make %s unique in column %s, which is too short for a +n suffix", value, column.Name)
+		}
+		previous = value
+	}
+}
+
+func (r *EmailRule) Apply(row *Row) error {
+	for _, columnName := range r.Columns {
+		column, ok := row.Table.Columns[columnName]
+		if !ok {
+			continue
+		}
+
+		datum, ok := row.Datum(column)
+		if !ok || datum.Kind() == types.KindNull {
+			continue
+		}
+		s, err := datum.ToString()
+		if err != nil {
+			return err
+		}
+		if len(s) == 0 {
+			continue
+		}
+		value, err := r.Pseudonymize(column, s)
+		if err != nil {
+			return err
+		}
+		row.SetValue(column, value)
+	}
+	return nil
+}
+
+func (r *EmailRule) CheckColumn(column *Column) error {
+	if !column.IsText() {
+		return fmt.Errorf("only character columns can hold email addresses")
+	}
+	return nil
+}
+
+func init() {
+	RegisterRule("email", emailRuleDefaultSpec, func(value cty.Value, block *hcl.Block, table *Table) (Rule, error) {
+		return NewEmailRule(value, table.Database.Config)
+	})
+}
+
+func NewEmailRule(value cty.Value, config *Config) (*EmailRule, error) {
+	rule := &EmailRule{}
+	err := gocty.FromCtyValue(value, &rule)
+	if err == nil {
+		switch rule.Local {
+		case "keep", "faker":
+		case "tokenize":
+			rule.Key, err = readKey(config, rule.KeyEnv, rule.KeyFile)
+		default:
+			err = fmt.Errorf("local must be one of keep, tokenize or faker")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
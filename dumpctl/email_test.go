@@ -0,0 +1,90 @@
+package dumpctl
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+)
+
+func TestPseudonymize(t *testing.T) {
+	column := &Column{Name: "email", Type: "varchar"}
+	tests := []struct {
+		rule    EmailRule
+		s, want string
+	}{
+		{EmailRule{Local: "keep", Domain: "example.com"}, "alice@corp.com", "alice@example.com"},
+		{EmailRule{Local: "keep", Domain: "example.com", KeepDomains: []string{"corp.com"}}, "alice@Corp.com", "alice@Corp.com"},
+		{EmailRule{Local: "keep", Domains: []string{"example.org"}}, "alice@corp.com", "alice@example.org"},
+		{EmailRule{Local: "keep", Domain: "example.com"}, "not an address", "not an address@example.com"},
+	}
+	for _, test := range tests {
+		got, err := test.rule.Pseudonymize(column, test.s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("Pseudonymize(%q) = %q, want %q", test.s, got, test.want)
+		}
+	}
+}
+
+func TestPseudonymizeConsistent(t *testing.T) {
+	column := &Column{Name: "email", Type: "varchar"}
+	for _, rule := range []*EmailRule{
+		{Local: "faker", Domain: "example.com"},
+		{Local: "tokenize", Domain: "example.com", Key: []byte("secret")},
+	} {
+		first, err := rule.Pseudonymize(column, "Alice@Corp.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := rule.Pseudonymize(column, "alice@corp.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if first != second {
+			t.Errorf("%s replaced the same address with %s and %s", rule.Local, first, second)
+		}
+		if first == "alice@example.com" {
+			t.Errorf("%s kept the local part", rule.Local)
+		}
+	}
+
+	rule := &EmailRule{Local: "tokenize", Domain: "example.com", Key: []byte("secret")}
+	got, _ := rule.Pseudonymize(column, "alice@corp.com")
+	if !regexp.MustCompile(`^[0-9a-f]{16}@example\.com$`).MatchString(got) {
+		t.Errorf("tokenized address %s is not a token at example.com", got)
+	}
+}
+
+func TestPseudonymizeUnique(t *testing.T) {
+	column := &Column{Name: "email", Type: "varchar", Key: "UNI", MaxLength: sql.NullInt64{Int64: 16, Valid: true}}
+	rule := &EmailRule{Local: "keep", Domain: "x.io"}
+	for _, want := range []string{"alice@x.io", "alice+1@x.io", "alice+2@x.io"} {
+		got, err := rule.Pseudonymize(column, "alice@corp.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+	for _, want := range []string{"longlocalpa@x.io", "longlocal+1@x.io"} {
+		got, err := rule.Pseudonymize(column, "longlocalpart@corp.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+
+	short := &Column{Name: "email", Type: "varchar", Key: "UNI", MaxLength: sql.NullInt64{Int64: 5, Valid: true}}
+	rule = &EmailRule{Local: "keep", Domain: "x.io"}
+	if _, err := rule.Pseudonymize(short, "alice@corp.com"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rule.Pseudonymize(short, "alice@corp.com"); err == nil {
+		t.Error("a duplicate that does not fit a +n suffix did not fail")
+	}
+}
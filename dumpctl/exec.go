@@ -0,0 +1,263 @@
+package dumpctl
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/pingcap/tidb/types"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+// ExecRule sends values to an external command and replaces them with the
+// values it returns. Each value is written to its stdin as a line of JSON:
+//
+//	{"table":"users","column":"email","type":"varchar","value":"a@b.com"}
+//
+// and the command must write one line of JSON for each, either
+// {"value":...} with the replacement or {"error":"..."} to stop the dump.
+type ExecRule struct {
+	Columns []string `cty:"columns"`
+	Command []string `cty:"command"`
+	Process *ExecProcess
+}
+
+// ExecProcess is an external command shared by all exec rules with the same
+// command. It is started for the first value and runs until the dump is
+// finished.
+type ExecProcess struct {
+	Command []string
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stdout  *bufio.Reader
+}
+
+var execRuleDefaultSpec = hcldec.ObjectSpec{
+	"columns": ColumnSpec,
+	"command": &hcldec.AttrSpec{
+		Name:     "command",
+		Type:     cty.List(cty.String),
+		Required: true,
+	},
+}
+
+type execRequest struct {
+	Table  string      `json:"table"`
+	Column string      `json:"column"`
+	Type   string      `json:"type"`
+	Value  interface{} `json:"value"`
+}
+
+type execResponse struct {
+	Value json.RawMessage `json:"value"`
+	Error string          `json:"error"`
+}
+
+func (p *ExecProcess) start() error {
+	p.cmd = exec.Command(p.Command[0], p.Command[1:]...)
+	p.cmd.Stderr = os.Stderr
+	stdin, err := p.cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := p.cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	p.stdin, p.stdout = stdin, bufio.NewReader(stdout)
+	return p.cmd.Start()
+}
+
+// Close stops the command by closing its stdin and waits for it to exit.
+func (p *ExecProcess) Close() error {
+	if p.cmd == nil {
+		return nil
+	}
+	p.stdin.Close()
+	err := p.cmd.Wait()
+	p.cmd = nil
+	if err != nil {
+		return fmt.Errorf("%s: %w", p.Command[0], err)
+	}
+	return nil
+}
+
+// execValue converts a datum of a column to JSON. Numbers are sent as numbers,
+// values of binary columns as base64, which keeps bytes that are not valid
+// UTF-8, and all other values as strings.
+func execValue(column *Column, datum types.Datum) (interface{}, error) {
+	switch {
+	case datum.Kind() == types.KindNull:
+		return nil, nil
+	case column.IsBinary():
+		s, err := datum.ToString()
+		if err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.EncodeToString([]byte(s)), nil
+	}
+	switch datum.Kind() {
+	case types.KindInt64:
+		return datum.GetInt64(), nil
+	case types.KindUint64:
+		return datum.GetUint64(), nil
+	case types.KindFloat32, types.KindFloat64:
+		return datum.GetFloat64(), nil
+	case types.KindMysqlDecimal:
+		return json.Number(datum.GetMysqlDecimal().String()), nil
+	}
+	return datum.ToString()
+}
+
+// columnValue converts a JSON value returned by the command to a value for
+// the column. Objects and arrays are written as JSON documents and strings
+// for binary columns are decoded from base64.
+func columnValue(column *Column, raw json.RawMessage) (interface{}, error) {
+	decoder := json.NewDecoder(strings.NewReader(string(raw)))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if column.IsBinary() {
+			b, err := base64.StdEncoding.DecodeString(v)
+			if err != nil {
+				return nil, fmt.Errorf("value for binary column %s is not base64: %w", column.Name, err)
+			}
+			return types.BinaryLiteral(b), nil
+		}
+		return v, nil
+	case bool:
+		if v {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		if column.Type == "decimal" || column.Type == "numeric" {
+			dec := new(types.MyDecimal)
+			err := dec.FromString([]byte(v.String()))
+			return dec, err
+		}
+		return v.Float64()
+	default:
+		return string(raw), nil
+	}
+}
+
+func (p *ExecProcess) exchange(request *execRequest) (json.RawMessage, error) {
+	if p.cmd == nil {
+		if err := p.start(); err != nil {
+			return nil, fmt.Errorf("could not start %s: %w", p.Command[0], err)
+		}
+	}
+	line, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.stdin.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("could not write to %s: %w", p.Command[0], err)
+	}
+	line, err = p.stdout.ReadBytes('\n')
+	if err != nil {
+		return nil, fmt.Errorf("could not read from %s: %w", p.Command[0], err)
+	}
+	var response execResponse
+	if err := json.Unmarshal(line, &response); err != nil {
+		return nil, fmt.Errorf("%s returned invalid JSON: %w", p.Command[0], err)
+	}
+	if len(response.Error) > 0 {
+		return nil, fmt.Errorf("%s: %s", p.Command[0], response.Error)
+	}
+	if response.Value == nil {
+		return nil, fmt.Errorf("%s returned no value for column %s", p.Command[0], request.Column)
+	}
+	return response.Value, nil
+}
+
+func (r *ExecRule) Apply(row *Row) error {
+	for _, columnName := range r.Columns {
+		column, ok := row.Table.Columns[columnName]
+		if !ok {
+			continue
+		}
+
+		datum, ok := row.Datum(column)
+		if !ok {
+			continue
+		}
+		value, err := execValue(column, datum)
+		if err != nil {
+			return err
+		}
+		raw, err := r.Process.exchange(&execRequest{
+			Table:  row.Table.Name,
+			Column: column.Name,
+			Type:   column.Type,
+			Value:  value,
+		})
+		if err != nil {
+			return err
+		}
+		result, err := columnValue(column, raw)
+		if err != nil {
+			return err
+		}
+		row.SetValue(column, result)
+	}
+	return nil
+}
+
+func init() {
+	RegisterRule("exec", execRuleDefaultSpec, func(value cty.Value, block *hcl.Block, table *Table) (Rule, error) {
+		return NewExecRule(value, table.Database.Config)
+	})
+}
+
+func NewExecRule(value cty.Value, config *Config) (*ExecRule, error) {
+	rule := &ExecRule{}
+	err := gocty.FromCtyValue(value, &rule)
+	if err == nil && len(rule.Command) == 0 {
+		err = fmt.Errorf("command must not be empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	rule.Process = config.ExecProcess(rule.Command)
+	return rule, nil
+}
+
+// ExecProcess returns the process for a command, so that it is started once
+// per dump however many rules use it. A program given as a path is relative
+// to the config file, other programs are looked up in PATH.
+func (c *Config) ExecProcess(command []string) *ExecProcess {
+	command = append([]string{}, command...)
+	if strings.ContainsRune(command[0], filepath.Separator) {
+		command[0] = c.Path(command[0])
+	}
+	key := strings.Join(command, "\x00")
+	if process, ok := c.ExecProcesses[key]; ok {
+		return process
+	}
+	if c.ExecProcesses == nil {
+		c.ExecProcesses = make(map[string]*ExecProcess)
+	}
+	process := &ExecProcess{Command: command}
+	c.ExecProcesses[key] = process
+	return process
+}
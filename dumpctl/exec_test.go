@@ -0,0 +1,35 @@
+package dumpctl
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/pingcap/tidb/types"
+)
+
+func TestExecBinaryValue(t *testing.T) {
+	column := &Column{Name: "avatar", Type: "blob", ColumnType: "blob"}
+	original := []byte{0x89, 'P', 'N', 'G', 0xff, 0x00}
+	value, err := execValue(column, types.NewBinaryLiteralDatum(types.BinaryLiteral(original)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != "iVBOR/8A" {
+		t.Errorf("execValue = %v, want iVBOR/8A", value)
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := columnValue(column, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, ok := result.(types.BinaryLiteral); !ok || !bytes.Equal(b, original) {
+		t.Errorf("columnValue = %v, want %v", result, original)
+	}
+	if _, err := columnValue(column, json.RawMessage(`"not base64!"`)); err == nil {
+		t.Error("a value that is not base64 was accepted")
+	}
+}
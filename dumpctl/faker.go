@@ -0,0 +1,192 @@
+package dumpctl
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type fakerLocale struct {
+	FirstNames      []string
+	LastNames       []string
+	Cities          []string
+	Streets         []string
+	StreetFormat    string
+	CompanySuffixes []string
+	PhoneFormats    []string
+	PostcodeFormat  string
+	Domains         []string
+}
+
+var fakerLocales = map[string]*fakerLocale{
+	"en": {
+		FirstNames:      []string{"James", "Mary", "Robert", "Patricia", "John", "Jennifer", "Michael", "Linda", "David", "Elizabeth", "William", "Barbara", "Richard", "Susan", "Joseph", "Jessica", "Thomas", "Sarah", "Charles", "Karen", "Daniel", "Nancy", "Matthew", "Lisa", "Anthony", "Betty", "Mark", "Sandra", "Steven", "Ashley"},
+		LastNames:       []string{"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Rodriguez", "Martinez", "Hernandez", "Lopez", "Wilson", "Anderson", "Taylor", "Thomas", "Moore", "Jackson", "Martin", "Lee", "Thompson", "White", "Harris", "Clark", "Lewis", "Robinson", "Walker", "Young", "Allen", "King"},
+		Cities:          []string{"Springfield", "Riverside", "Franklin", "Greenville", "Bristol", "Clinton", "Fairview", "Salem", "Madison", "Georgetown", "Arlington", "Ashland", "Oxford", "Dover", "Jackson", "Burlington", "Manchester", "Milton", "Newport", "Auburn"},
+		Streets:         []string{"Main", "Oak", "Pine", "Maple", "Cedar", "Elm", "Washington", "Lake", "Hill", "Park", "Walnut", "Sunset", "Lincoln", "Jackson", "Church", "Highland", "Willow", "Spring", "Ridge", "Meadow"},
+		StreetFormat:    "{number} {street} {suffix}",
+		CompanySuffixes: []string{"Inc", "LLC", "Group", "and Sons", "Partners", "Holdings", "Labs", "Co"},
+		PhoneFormats:    []string{"(###) 555-####", "###-555-####", "+1 ### 555 ####"},
+		PostcodeFormat:  "#####",
+		Domains:         []string{"example.com", "example.net", "example.org"},
+	},
+	"de": {
+		FirstNames:      []string{"Lukas", "Anna", "Leon", "Emma", "Finn", "Mia", "Jonas", "Hannah", "Paul", "Sofia", "Felix", "Lena", "Elias", "Marie", "Maximilian", "Lea", "Noah", "Clara", "Ben", "Laura"},
+		LastNames:       []string{"Müller", "Schmidt", "Schneider", "Fischer", "Weber", "Meyer", "Wagner", "Becker", "Schulz", "Hoffmann", "Schäfer", "Koch", "Bauer", "Richter", "Klein", "Wolf", "Schröder", "Neumann", "Schwarz", "Zimmermann"},
+		Cities:          []string{"Berlin", "Hamburg", "München", "Köln", "Frankfurt", "Stuttgart", "Düsseldorf", "Leipzig", "Dortmund", "Essen", "Bremen", "Dresden", "Hannover", "Nürnberg", "Bonn"},
+		Streets:         []string{"Haupt", "Schul", "Garten", "Bahnhof", "Dorf", "Berg", "Birken", "Linden", "Kirch", "Wald", "Ring", "Mühlen", "Wiesen", "Rosen", "Feld"},
+		StreetFormat:    "{street}{suffix} {number}",
+		CompanySuffixes: []string{"GmbH", "AG", "KG", "GmbH & Co. KG", "e.V."},
+		PhoneFormats:    []string{"+49 ### #######", "0### ######"},
+		PostcodeFormat:  "#####",
+		Domains:         []string{"example.de", "example.com"},
+	},
+	"fr": {
+		FirstNames:      []string{"Gabriel", "Louise", "Léo", "Jade", "Raphaël", "Emma", "Arthur", "Alice", "Louis", "Chloé", "Jules", "Lina", "Adam", "Léa", "Hugo", "Manon", "Lucas", "Camille", "Nathan", "Inès"},
+		LastNames:       []string{"Martin", "Bernard", "Thomas", "Petit", "Robert", "Richard", "Durand", "Dubois", "Moreau", "Laurent", "Simon", "Michel", "Lefebvre", "Leroy", "Roux", "David", "Bertrand", "Morel", "Fournier", "Girard"},
+		Cities:          []string{"Paris", "Marseille", "Lyon", "Toulouse", "Nice", "Nantes", "Strasbourg", "Montpellier", "Bordeaux", "Lille", "Rennes", "Reims", "Dijon", "Grenoble", "Angers"},
+		Streets:         []string{"de la Paix", "Victor Hugo", "de la République", "du Moulin", "des Écoles", "de l'Église", "Pasteur", "Jean Jaurès", "de la Gare", "des Lilas"},
+		StreetFormat:    "{number} {suffix} {street}",
+		CompanySuffixes: []string{"SA", "SARL", "SAS", "et Fils"},
+		PhoneFormats:    []string{"+33 # ## ## ## ##", "0# ## ## ## ##"},
+		PostcodeFormat:  "#####",
+		Domains:         []string{"example.fr", "example.com"},
+	},
+}
+
+var streetSuffixes = map[string][]string{
+	"en": {"Street", "Avenue", "Road", "Lane", "Drive", "Court", "Way", "Boulevard"},
+	"de": {"straße", "weg", "allee", "gasse", "platz"},
+	"fr": {"rue", "avenue", "boulevard", "place", "allée"},
+}
+
+var loremWords = []string{
+	"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do",
+	"eiusmod", "tempor", "incididunt", "ut", "labore", "et", "dolore", "magna", "aliqua", "enim",
+	"ad", "minim", "veniam", "quis", "nostrud", "exercitation", "ullamco", "laboris", "nisi", "aliquip",
+	"ex", "ea", "commodo", "consequat", "duis", "aute", "irure", "in", "reprehenderit", "voluptate",
+}
+
+type fakerGenerator func(r *rand.Rand, locale string) string
+
+var fakerGenerators = map[string]fakerGenerator{
+	"first_name": func(r *rand.Rand, locale string) string {
+		return pick(r, fakerLocales[locale].FirstNames)
+	},
+	"last_name": func(r *rand.Rand, locale string) string {
+		return pick(r, fakerLocales[locale].LastNames)
+	},
+	"name": func(r *rand.Rand, locale string) string {
+		l := fakerLocales[locale]
+		return fmt.Sprintf("%s %s", pick(r, l.FirstNames), pick(r, l.LastNames))
+	},
+	"username": func(r *rand.Rand, locale string) string {
+		l := fakerLocales[locale]
+		return asciiLower(fmt.Sprintf("%s.%s%d", pick(r, l.FirstNames), pick(r, l.LastNames), r.Intn(1000)))
+	},
+	"email": func(r *rand.Rand, locale string) string {
+		l := fakerLocales[locale]
+		local := asciiLower(fmt.Sprintf("%s.%s%d", pick(r, l.FirstNames), pick(r, l.LastNames), r.Intn(1000)))
+		return fmt.Sprintf("%s@%s", local, pick(r, l.Domains))
+	},
+	"phone": func(r *rand.Rand, locale string) string {
+		return digits(r, pick(r, fakerLocales[locale].PhoneFormats))
+	},
+	"street_address": func(r *rand.Rand, locale string) string {
+		l := fakerLocales[locale]
+		return strings.NewReplacer(
+			"{number}", strconv.Itoa(1+r.Intn(1999)),
+			"{street}", pick(r, l.Streets),
+			"{suffix}", pick(r, streetSuffixes[locale]),
+		).Replace(l.StreetFormat)
+	},
+	"city": func(r *rand.Rand, locale string) string {
+		return pick(r, fakerLocales[locale].Cities)
+	},
+	"postcode": func(r *rand.Rand, locale string) string {
+		return digits(r, fakerLocales[locale].PostcodeFormat)
+	},
+	"company": func(r *rand.Rand, locale string) string {
+		l := fakerLocales[locale]
+		return fmt.Sprintf("%s %s", pick(r, l.LastNames), pick(r, l.CompanySuffixes))
+	},
+	"word": func(r *rand.Rand, locale string) string {
+		return pick(r, loremWords)
+	},
+	"lorem": func(r *rand.Rand, locale string) string {
+		words := make([]string, 6+r.Intn(10))
+		for i := range words {
+			words[i] = pick(r, loremWords)
+		}
+		sentence := strings.Join(words, " ")
+		return strings.ToUpper(sentence[:1]) + sentence[1:] + "."
+	},
+	"uuid": func(r *rand.Rand, locale string) string {
+		b := make([]byte, 16)
+		r.Read(b)
+		b[6] = (b[6] & 0x0f) | 0x40
+		b[8] = (b[8] & 0x3f) | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+	},
+}
+
+// seededRand returns a random source seeded from the given parts so that the
+// same input always produces the same sequence.
+func seededRand(parts ...string) *rand.Rand {
+	h := fnv.New64a()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return rand.New(rand.NewSource(int64(h.Sum64())))
+}
+
+func pick(r *rand.Rand, choices []string) string {
+	return choices[r.Intn(len(choices))]
+}
+
+// digits replaces every # in format with a random digit.
+func digits(r *rand.Rand, format string) string {
+	var sb strings.Builder
+	for _, c := range format {
+		if c == '#' {
+			sb.WriteByte(byte('0' + r.Intn(10)))
+		} else {
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
+
+func asciiLower(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return -1
+	}, s)
+}
+
+func fakerGeneratorNames() []string {
+	names := make([]string, 0, len(fakerGenerators))
+	for name := range fakerGenerators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func fakerLocaleNames() []string {
+	names := make([]string, 0, len(fakerLocales))
+	for name := range fakerLocales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,160 @@
+package dumpctl
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// fpeMinDomain is the smallest number of possible values NIST SP 800-38G
+// allows a format-preserving cipher to encrypt.
+const fpeMinDomain = 1000000
+
+// fpeTooShort reports whether n numerals in radix are too few to encrypt.
+func fpeTooShort(radix, n int) bool {
+	return n < 2 || math.Pow(float64(radix), float64(n)) < fpeMinDomain
+}
+
+// numeralsToInt interprets numerals as a big-endian number in radix.
+func numeralsToInt(numerals []int, radix int) *big.Int {
+	n := new(big.Int)
+	r := big.NewInt(int64(radix))
+	for _, numeral := range numerals {
+		n.Mul(n, r)
+		n.Add(n, big.NewInt(int64(numeral)))
+	}
+	return n
+}
+
+// intToNumerals writes n as exactly length big-endian numerals in radix.
+func intToNumerals(n *big.Int, radix, length int) []int {
+	numerals := make([]int, length)
+	n = new(big.Int).Set(n)
+	r := big.NewInt(int64(radix))
+	m := new(big.Int)
+	for i := length - 1; i >= 0; i-- {
+		n.DivMod(n, r, m)
+		numerals[i] = int(m.Int64())
+	}
+	return numerals
+}
+
+// ff1 implements the FF1 format-preserving encryption mode of NIST SP
+// 800-38G.
+type ff1 struct {
+	block cipher.Block
+	radix int
+	tweak []byte
+}
+
+func newFF1(key []byte, radix int, tweak []byte) (*ff1, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &ff1{block: block, radix: radix, tweak: tweak}, nil
+}
+
+// prf is AES-CBC-MAC with a zero IV over data, which is a multiple of the
+// block size.
+func (c *ff1) prf(data []byte) []byte {
+	y := make([]byte, aes.BlockSize)
+	for i := 0; i < len(data); i += aes.BlockSize {
+		for j := range y {
+			y[j] ^= data[i+j]
+		}
+		c.block.Encrypt(y, y)
+	}
+	return y
+}
+
+func (c *ff1) checkLength(n int) error {
+	if fpeTooShort(c.radix, n) {
+		return fmt.Errorf("%d characters in radix %d are too few to encrypt", n, c.radix)
+	}
+	return nil
+}
+
+// round computes the value y that is added to or subtracted from one half of
+// the input in round i.
+func (c *ff1) round(i, u, n, b, d int, half []int) *big.Int {
+	t := len(c.tweak)
+	p := []byte{1, 2, 1, 0, 0, 0, 10, byte(u % 256), 0, 0, 0, 0, 0, 0, 0, 0}
+	p[3], p[4], p[5] = byte(c.radix>>16), byte(c.radix>>8), byte(c.radix)
+	binary.BigEndian.PutUint32(p[8:12], uint32(n))
+	binary.BigEndian.PutUint32(p[12:16], uint32(t))
+
+	padding := ((-t-b-1)%16 + 16) % 16
+	q := make([]byte, 0, t+padding+1+b)
+	q = append(q, c.tweak...)
+	q = append(q, make([]byte, padding)...)
+	q = append(q, byte(i))
+	num := numeralsToInt(half, c.radix).Bytes()
+	q = append(q, make([]byte, b-len(num))...)
+	q = append(q, num...)
+
+	r := c.prf(append(p, q...))
+	s := make([]byte, 0, d+aes.BlockSize)
+	s = append(s, r...)
+	for j := 1; len(s) < d; j++ {
+		block := make([]byte, aes.BlockSize)
+		binary.BigEndian.PutUint64(block[8:], uint64(j))
+		for k := range block {
+			block[k] ^= r[k]
+		}
+		c.block.Encrypt(block, block)
+		s = append(s, block...)
+	}
+	return new(big.Int).SetBytes(s[:d])
+}
+
+func (c *ff1) params(n int) (u, v, b, d int) {
+	u = n / 2
+	v = n - u
+	b = int(math.Ceil(math.Ceil(float64(v)*math.Log2(float64(c.radix))) / 8))
+	d = 4*((b+3)/4) + 4
+	return
+}
+
+func (c *ff1) Encrypt(x []int) ([]int, error) {
+	n := len(x)
+	if err := c.checkLength(n); err != nil {
+		return nil, err
+	}
+	u, v, b, d := c.params(n)
+	a, bb := x[:u], x[u:]
+	for i := 0; i < 10; i++ {
+		m := u
+		if i%2 == 1 {
+			m = v
+		}
+		y := c.round(i, u, n, b, d, bb)
+		modulus := new(big.Int).Exp(big.NewInt(int64(c.radix)), big.NewInt(int64(m)), nil)
+		sum := new(big.Int).Add(numeralsToInt(a, c.radix), y)
+		a, bb = bb, intToNumerals(sum.Mod(sum, modulus), c.radix, m)
+	}
+	return append(append([]int{}, a...), bb...), nil
+}
+
+func (c *ff1) Decrypt(x []int) ([]int, error) {
+	n := len(x)
+	if err := c.checkLength(n); err != nil {
+		return nil, err
+	}
+	u, v, b, d := c.params(n)
+	a, bb := x[:u], x[u:]
+	for i := 9; i >= 0; i-- {
+		m := u
+		if i%2 == 1 {
+			m = v
+		}
+		y := c.round(i, u, n, b, d, a)
+		modulus := new(big.Int).Exp(big.NewInt(int64(c.radix)), big.NewInt(int64(m)), nil)
+		diff := new(big.Int).Sub(numeralsToInt(bb, c.radix), y)
+		a, bb = intToNumerals(diff.Mod(diff, modulus), c.radix, m), a
+	}
+	return append(append([]int{}, a...), bb...), nil
+}
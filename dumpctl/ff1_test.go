@@ -0,0 +1,87 @@
+package dumpctl
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+const testNumerals = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+func toNumerals(s string) []int {
+	x := make([]int, len(s))
+	for i, c := range s {
+		x[i] = strings.IndexRune(testNumerals, c)
+	}
+	return x
+}
+
+func fromNumerals(x []int) string {
+	var sb strings.Builder
+	for _, numeral := range x {
+		sb.WriteByte(testNumerals[numeral])
+	}
+	return sb.String()
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// The samples of NIST SP 800-38G for FF1.
+var ff1Samples = []struct {
+	key, tweak            string
+	radix                 int
+	plaintext, ciphertext string
+}{
+	{"2B7E151628AED2A6ABF7158809CF4F3C", "", 10, "0123456789", "2433477484"},
+	{"2B7E151628AED2A6ABF7158809CF4F3C", "39383736353433323130", 10, "0123456789", "6124200773"},
+	{"2B7E151628AED2A6ABF7158809CF4F3C", "3737373770717273373737", 36, "0123456789abcdefghi", "a9tv40mll9kdu509eum"},
+	{"2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F", "", 10, "0123456789", "2830668132"},
+	{"2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F", "39383736353433323130", 10, "0123456789", "2496655549"},
+	{"2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F", "3737373770717273373737", 36, "0123456789abcdefghi", "xbj3kv35jrawxv32ysr"},
+	{"2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F7F036D6F04FC6A94", "", 10, "0123456789", "6657667009"},
+	{"2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F7F036D6F04FC6A94", "39383736353433323130", 10, "0123456789", "1001623463"},
+	{"2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F7F036D6F04FC6A94", "3737373770717273373737", 36, "0123456789abcdefghi", "xs8a0azh2avyalyzuwd"},
+}
+
+func TestFF1Samples(t *testing.T) {
+	for i, sample := range ff1Samples {
+		c, err := newFF1(mustDecodeHex(t, sample.key), sample.radix, mustDecodeHex(t, sample.tweak))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ciphertext, err := c.Encrypt(toNumerals(sample.plaintext))
+		if err != nil {
+			t.Fatalf("sample %d: %v", i+1, err)
+		}
+		if got := fromNumerals(ciphertext); got != sample.ciphertext {
+			t.Errorf("sample %d: encrypted %s to %s, want %s", i+1, sample.plaintext, got, sample.ciphertext)
+		}
+		plaintext, err := c.Decrypt(toNumerals(sample.ciphertext))
+		if err != nil {
+			t.Fatalf("sample %d: %v", i+1, err)
+		}
+		if got := fromNumerals(plaintext); got != sample.plaintext {
+			t.Errorf("sample %d: decrypted %s to %s, want %s", i+1, sample.ciphertext, got, sample.plaintext)
+		}
+	}
+}
+
+func TestFF1Length(t *testing.T) {
+	c, err := newFF1(mustDecodeHex(t, ff1Samples[0].key), 10, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Encrypt(toNumerals("12345")); err == nil {
+		t.Error("encrypted 5 digits, which are fewer than a million values")
+	}
+	if _, err := c.Encrypt(toNumerals("123456")); err != nil {
+		t.Errorf("could not encrypt 6 digits: %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package dumpctl
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// ff3 implements the FF3-1 format-preserving encryption mode of NIST SP
+// 800-38G Revision 1.
+type ff3 struct {
+	block                 cipher.Block
+	radix                 int
+	tweakLeft, tweakRight []byte
+}
+
+func reverseBytes(b []byte) []byte {
+	reversed := make([]byte, len(b))
+	for i := range b {
+		reversed[len(b)-1-i] = b[i]
+	}
+	return reversed
+}
+
+func reverseNumerals(x []int) []int {
+	reversed := make([]int, len(x))
+	for i := range x {
+		reversed[len(x)-1-i] = x[i]
+	}
+	return reversed
+}
+
+// newFF3 creates an FF3-1 cipher from a 56 bit tweak.
+func newFF3(key []byte, radix int, tweak []byte) (*ff3, error) {
+	if len(tweak) != 7 {
+		return nil, fmt.Errorf("FF3-1 tweaks must be 7 bytes long")
+	}
+	left := []byte{tweak[0], tweak[1], tweak[2], tweak[3] & 0xf0}
+	right := []byte{tweak[4], tweak[5], tweak[6], tweak[3] << 4}
+	return newFF3WithHalves(key, radix, left, right)
+}
+
+// newFF3WithHalves creates a cipher from the two halves of the tweak, which
+// is how the original FF3 mode splits a 64 bit tweak.
+func newFF3WithHalves(key []byte, radix int, left, right []byte) (*ff3, error) {
+	block, err := aes.NewCipher(reverseBytes(key))
+	if err != nil {
+		return nil, err
+	}
+	return &ff3{block: block, radix: radix, tweakLeft: left, tweakRight: right}, nil
+}
+
+func (c *ff3) checkLength(n int) error {
+	maxLength := 2 * int(math.Floor(96/math.Log2(float64(c.radix))))
+	if fpeTooShort(c.radix, n) {
+		return fmt.Errorf("%d characters in radix %d are too few to encrypt", n, c.radix)
+	}
+	if n > maxLength {
+		return fmt.Errorf("%d characters in radix %d are too many to encrypt, the maximum is %d", n, c.radix, maxLength)
+	}
+	return nil
+}
+
+func (c *ff3) round(i int, half []int) *big.Int {
+	w := c.tweakRight
+	if i%2 == 1 {
+		w = c.tweakLeft
+	}
+	p := make([]byte, 16)
+	copy(p, w)
+	p[3] ^= byte(i)
+	num := numeralsToInt(reverseNumerals(half), c.radix).Bytes()
+	copy(p[16-len(num):], num)
+
+	s := reverseBytes(p)
+	c.block.Encrypt(s, s)
+	return new(big.Int).SetBytes(reverseBytes(s))
+}
+
+func (c *ff3) Encrypt(x []int) ([]int, error) {
+	n := len(x)
+	if err := c.checkLength(n); err != nil {
+		return nil, err
+	}
+	u := (n + 1) / 2
+	v := n - u
+	a, b := x[:u], x[u:]
+	for i := 0; i < 8; i++ {
+		m := u
+		if i%2 == 1 {
+			m = v
+		}
+		y := c.round(i, b)
+		modulus := new(big.Int).Exp(big.NewInt(int64(c.radix)), big.NewInt(int64(m)), nil)
+		sum := new(big.Int).Add(numeralsToInt(reverseNumerals(a), c.radix), y)
+		a, b = b, reverseNumerals(intToNumerals(sum.Mod(sum, modulus), c.radix, m))
+	}
+	return append(append([]int{}, a...), b...), nil
+}
+
+func (c *ff3) Decrypt(x []int) ([]int, error) {
+	n := len(x)
+	if err := c.checkLength(n); err != nil {
+		return nil, err
+	}
+	u := (n + 1) / 2
+	v := n - u
+	a, b := x[:u], x[u:]
+	for i := 7; i >= 0; i-- {
+		m := u
+		if i%2 == 1 {
+			m = v
+		}
+		y := c.round(i, a)
+		modulus := new(big.Int).Exp(big.NewInt(int64(c.radix)), big.NewInt(int64(m)), nil)
+		diff := new(big.Int).Sub(numeralsToInt(reverseNumerals(b), c.radix), y)
+		a, b = reverseNumerals(intToNumerals(diff.Mod(diff, modulus), c.radix, m)), a
+	}
+	return append(append([]int{}, a...), b...), nil
+}
@@ -0,0 +1,57 @@
+package dumpctl
+
+import "testing"
+
+// The samples of NIST SP 800-38G for the original FF3 mode with its 64 bit
+// tweak, which FF3-1 shares its rounds with.
+var ff3Samples = []struct {
+	key, tweak            string
+	radix                 int
+	plaintext, ciphertext string
+}{
+	{"EF4359D8D580AA4F7F036D6F04FC6A94", "D8E7920AFA330A73", 10, "890121234567890000", "750918814058654607"},
+	{"EF4359D8D580AA4F7F036D6F04FC6A94", "9A768A92F60E12D8", 10, "890121234567890000", "018989839189395384"},
+	{"EF4359D8D580AA4F7F036D6F04FC6A94", "D8E7920AFA330A73", 10, "89012123456789000000789000000", "48598367162252569629397416226"},
+	{"EF4359D8D580AA4F7F036D6F04FC6A94", "0000000000000000", 10, "89012123456789000000789000000", "34695224821734535122613701434"},
+	{"EF4359D8D580AA4F7F036D6F04FC6A94", "9A768A92F60E12D8", 26, "0123456789abcdefghi", "g2pk40i992fn20cjakb"},
+}
+
+func TestFF3Samples(t *testing.T) {
+	for i, sample := range ff3Samples {
+		tweak := mustDecodeHex(t, sample.tweak)
+		c, err := newFF3WithHalves(mustDecodeHex(t, sample.key), sample.radix, tweak[:4], tweak[4:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		ciphertext, err := c.Encrypt(toNumerals(sample.plaintext))
+		if err != nil {
+			t.Fatalf("sample %d: %v", i+1, err)
+		}
+		if got := fromNumerals(ciphertext); got != sample.ciphertext {
+			t.Errorf("sample %d: encrypted %s to %s, want %s", i+1, sample.plaintext, got, sample.ciphertext)
+		}
+		plaintext, err := c.Decrypt(toNumerals(sample.ciphertext))
+		if err != nil {
+			t.Fatalf("sample %d: %v", i+1, err)
+		}
+		if got := fromNumerals(plaintext); got != sample.plaintext {
+			t.Errorf("sample %d: decrypted %s to %s, want %s", i+1, sample.ciphertext, got, sample.plaintext)
+		}
+	}
+}
+
+// TestFF31 checks the 56 bit tweak of FF3-1 against a published FF3-1 test
+// vector.
+func TestFF31(t *testing.T) {
+	c, err := newFF3(mustDecodeHex(t, "EF4359D8D580AA4F7F036D6F04FC6A94"), 10, mustDecodeHex(t, "D8E7920AFA330A"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, err := c.Encrypt(toNumerals("890121234567890000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fromNumerals(ciphertext), "477064185124354662"; got != want {
+		t.Errorf("encrypted to %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,282 @@
+package dumpctl
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/pingcap/tidb/types"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+var fpeAlphabets = map[string]string{
+	"digits":       "0123456789",
+	"alphanumeric": "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz",
+}
+
+type fpeCipher interface {
+	Encrypt([]int) ([]int, error)
+	Decrypt([]int) ([]int, error)
+}
+
+// FPERule encrypts values with a format-preserving cipher so that they keep
+// their shape but can be decrypted by anyone holding the key.
+type FPERule struct {
+	Columns   []string `cty:"columns"`
+	KeyEnv    string   `cty:"key_env"`
+	KeyFile   *string  `cty:"key_file"`
+	Algorithm string   `cty:"algorithm"`
+	Tweak     string   `cty:"tweak"`
+	Alphabet  string   `cty:"alphabet"`
+	// Short is what happens to values with too few characters of the
+	// alphabet to encrypt: fail or skip.
+	Short string `cty:"short"`
+	// Decimal encrypts integer columns and Text encrypts the characters of
+	// other columns that are part of the alphabet.
+	Decimal fpeCipher
+	Text    fpeCipher
+}
+
+var fpeRuleDefaultSpec = hcldec.ObjectSpec{
+	"columns": ColumnSpec,
+	"key_env": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "key_env",
+			Type: cty.String,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.StringVal("DUMPCTL_FPE_KEY")},
+	},
+	"key_file": &hcldec.AttrSpec{
+		Name: "key_file",
+		Type: cty.String,
+	},
+	"algorithm": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "algorithm",
+			Type: cty.String,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.StringVal("ff1")},
+	},
+	"tweak": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "tweak",
+			Type: cty.String,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.StringVal("")},
+	},
+	"alphabet": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "alphabet",
+			Type: cty.String,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.StringVal("digits")},
+	},
+	"short": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "short",
+			Type: cty.String,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.StringVal("fail")},
+	},
+}
+
+func (r *FPERule) newCipher(key []byte, radix int) (fpeCipher, error) {
+	switch r.Algorithm {
+	case "ff1":
+		return newFF1(key, radix, []byte(r.Tweak))
+	case "ff3-1":
+		tweak := sha256.Sum256([]byte(r.Tweak))
+		return newFF3(key, radix, tweak[:7])
+	default:
+		return nil, fmt.Errorf("algorithm must be ff1 or ff3-1")
+	}
+}
+
+// transformString encrypts or decrypts the characters of s that are part of
+// the alphabet and leaves all others in place. Values without any of these
+// characters are left as they are, as are values with too few of them if
+// short values are skipped.
+func (r *FPERule) transformString(s string, encrypt bool) (string, error) {
+	alphabet := fpeAlphabets[r.Alphabet]
+	runes := []rune(s)
+	var positions, numerals []int
+	for i, c := range runes {
+		if numeral := strings.IndexRune(alphabet, c); numeral >= 0 {
+			positions = append(positions, i)
+			numerals = append(numerals, numeral)
+		}
+	}
+	if len(numerals) == 0 {
+		return s, nil
+	}
+	if fpeTooShort(len(alphabet), len(numerals)) {
+		if r.Short == "skip" {
+			return s, nil
+		}
+		return "", fmt.Errorf("%d characters of the alphabet are too few to encrypt, set short = \"skip\" to leave such values unencrypted", len(numerals))
+	}
+	var err error
+	if encrypt {
+		numerals, err = r.Text.Encrypt(numerals)
+	} else {
+		numerals, err = r.Text.Decrypt(numerals)
+	}
+	if err != nil {
+		return "", err
+	}
+	for i, position := range positions {
+		runes[position] = rune(alphabet[numerals[i]])
+	}
+	return string(runes), nil
+}
+
+// transformInteger encrypts or decrypts an integer so that the result stays
+// within the column's range. The cipher works on a fixed number of digits, so
+// results outside of the range are encrypted again until one fits (cycle
+// walking), which decryption mirrors.
+func (r *FPERule) transformInteger(column *Column, datum types.Datum, encrypt bool) (interface{}, error) {
+	s, err := datum.ToString()
+	if err != nil {
+		return nil, err
+	}
+	negative := strings.HasPrefix(s, "-")
+	n, ok := new(big.Int).SetString(strings.TrimPrefix(s, "-"), 10)
+	if !ok {
+		return nil, fmt.Errorf("cannot encrypt %s in integer column %s", s, column.Name)
+	}
+	// negative values are encrypted to a magnitude up to that of the
+	// column's minimum, all others up to its maximum
+	minValue, maxValue := column.IntegerRange()
+	max := new(big.Int).SetUint64(maxValue)
+	if negative {
+		max.Neg(big.NewInt(minValue))
+	}
+	if n.Cmp(max) > 0 {
+		return nil, fmt.Errorf("cannot encrypt %s in column %s", s, column.Name)
+	}
+
+	width := len(strconv.FormatUint(maxValue, 10))
+	for {
+		numerals := intToNumerals(n, 10, width)
+		if encrypt {
+			numerals, err = r.Decimal.Encrypt(numerals)
+		} else {
+			numerals, err = r.Decimal.Decrypt(numerals)
+		}
+		if err != nil {
+			return nil, err
+		}
+		n = numeralsToInt(numerals, 10)
+		if n.Cmp(max) <= 0 && !(negative && n.Sign() == 0) {
+			break
+		}
+	}
+
+	if negative {
+		return n.Neg(n).Int64(), nil
+	}
+	return n.Uint64(), nil
+}
+
+func (r *FPERule) transform(row *Row, encrypt bool) error {
+	for _, columnName := range r.Columns {
+		column, ok := row.Table.Columns[columnName]
+		if !ok {
+			continue
+		}
+
+		datum, ok := row.Datum(column)
+		if !ok || datum.Kind() == types.KindNull {
+			continue
+		}
+		switch {
+		case column.IsInteger():
+			value, err := r.transformInteger(column, datum, encrypt)
+			if err != nil {
+				return err
+			}
+			row.SetValue(column, value)
+		case column.IsText():
+			s, err := datum.ToString()
+			if err != nil {
+				return err
+			}
+			value, err := r.transformString(s, encrypt)
+			if err != nil {
+				return fmt.Errorf("cannot encrypt column %s: %w", column.Name, err)
+			}
+			row.SetValue(column, value)
+		default:
+			return fmt.Errorf("cannot encrypt column %s with type %s", column.Name, column.Type)
+		}
+	}
+	return nil
+}
+
+func (r *FPERule) Apply(row *Row) error {
+	return r.transform(row, true)
+}
+
+func (r *FPERule) Reverse(row *Row) error {
+	return r.transform(row, false)
+}
+
+func (r *FPERule) CheckColumn(column *Column) error {
+	if !column.IsInteger() && !column.IsText() {
+		return fmt.Errorf("only integer and character columns can be encrypted")
+	}
+	// the cipher needs a domain of at least fpeMinDomain values, and cycle
+	// walking a much larger domain down to the column's range takes thousands
+	// of encryptions for every value
+	if _, max := column.IntegerRange(); column.IsInteger() && max < fpeMinDomain-1 {
+		return fmt.Errorf("integer columns with fewer than %d values cannot be encrypted", fpeMinDomain)
+	}
+	return nil
+}
+
+func init() {
+	RegisterReversibleRule("fpe", fpeRuleDefaultSpec, func(value cty.Value, block *hcl.Block, table *Table) (Rule, error) {
+		return NewFPERule(value, table.Database.Config)
+	})
+}
+
+func NewFPERule(value cty.Value, config *Config) (*FPERule, error) {
+	rule := &FPERule{}
+	err := gocty.FromCtyValue(value, &rule)
+	if err == nil {
+		err = rule.configureCiphers(config)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *FPERule) configureCiphers(config *Config) error {
+	alphabet, ok := fpeAlphabets[r.Alphabet]
+	if !ok {
+		return fmt.Errorf("alphabet must be digits or alphanumeric")
+	}
+	if r.Short != "fail" && r.Short != "skip" {
+		return fmt.Errorf("short must be fail or skip")
+	}
+	hexKey, err := readKey(config, r.KeyEnv, r.KeyFile)
+	if err != nil {
+		return err
+	}
+	key, err := hex.DecodeString(string(hexKey))
+	if err != nil {
+		return fmt.Errorf("key must be hex encoded: %w", err)
+	}
+	if r.Decimal, err = r.newCipher(key, 10); err != nil {
+		return err
+	}
+	r.Text, err = r.newCipher(key, len(alphabet))
+	return err
+}
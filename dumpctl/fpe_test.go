@@ -0,0 +1,87 @@
+package dumpctl
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/pingcap/tidb/types"
+)
+
+func TestFPEIntegerRange(t *testing.T) {
+	cipher, err := newFF1(mustDecodeHex(t, "2B7E151628AED2A6ABF7158809CF4F3C"), 10, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rule := &FPERule{Decimal: cipher}
+	tests := []struct {
+		columnType string
+		values     []int64
+	}{
+		{"int", []int64{-2147483648, -1, 0, 1, 2147483647}},
+		{"mediumint", []int64{-8388608, -42, 42, 8388607}},
+		{"bigint", []int64{-9223372036854775808, -1, 0, 9223372036854775807}},
+	}
+	for _, test := range tests {
+		column := &Column{Name: "n", Type: test.columnType, ColumnType: test.columnType}
+		min, max := column.IntegerRange()
+		for _, value := range test.values {
+			encrypted, err := rule.transformInteger(column, types.NewIntDatum(value), true)
+			if err != nil {
+				t.Errorf("encrypting %d in %s: %v", value, test.columnType, err)
+				continue
+			}
+			var datum types.Datum
+			switch v := encrypted.(type) {
+			case int64:
+				if v < min || v >= 0 {
+					t.Errorf("%d in %s encrypted to %d", value, test.columnType, v)
+				}
+				datum = types.NewIntDatum(v)
+			case uint64:
+				if v > max || value < 0 {
+					t.Errorf("%d in %s encrypted to %d", value, test.columnType, v)
+				}
+				datum = types.NewUintDatum(v)
+			}
+			decrypted, err := rule.transformInteger(column, datum, false)
+			if err != nil {
+				t.Errorf("decrypting %v in %s: %v", encrypted, test.columnType, err)
+				continue
+			}
+			if got := strconv.FormatInt(value, 10); got != formatInteger(decrypted) {
+				t.Errorf("%d in %s decrypted to %v", value, test.columnType, decrypted)
+			}
+		}
+	}
+}
+
+func formatInteger(value interface{}) string {
+	switch v := value.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	}
+	return ""
+}
+
+func TestFPECheckColumn(t *testing.T) {
+	rule := &FPERule{}
+	tests := []struct {
+		dataType, columnType string
+		ok                   bool
+	}{
+		{"tinyint", "tinyint", false},
+		{"smallint", "smallint unsigned", false},
+		{"mediumint", "mediumint", true},
+		{"bigint", "bigint unsigned", true},
+		{"varchar", "varchar(20)", true},
+		{"datetime", "datetime", false},
+	}
+	for _, test := range tests {
+		column := &Column{Name: "n", Type: test.dataType, ColumnType: test.columnType}
+		if err := rule.CheckColumn(column); (err == nil) != test.ok {
+			t.Errorf("CheckColumn(%s) = %v", test.columnType, err)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package dumpctl
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/pingcap/tidb/types"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+var hashAlgorithms = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"sha1":   sha1.New,
+	"md5":    md5.New,
+	"xxhash": func() hash.Hash { return xxhash.New() },
+}
+
+// HashRule replaces values with an unkeyed digest of the salted value.
+type HashRule struct {
+	Columns   []string `cty:"columns"`
+	Algorithm string   `cty:"algorithm"`
+	Salt      string   `cty:"salt"`
+	Encoding  string   `cty:"encoding"`
+}
+
+var hashRuleDefaultSpec = hcldec.ObjectSpec{
+	"columns": ColumnSpec,
+	"algorithm": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "algorithm",
+			Type: cty.String,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.StringVal("sha256")},
+	},
+	"salt": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "salt",
+			Type: cty.String,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.StringVal("")},
+	},
+	"encoding": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "encoding",
+			Type: cty.String,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.StringVal("hex")},
+	},
+}
+
+func (r *HashRule) digest(s string) []byte {
+	h := hashAlgorithms[r.Algorithm]()
+	h.Write([]byte(r.Salt))
+	h.Write([]byte(s))
+	return h.Sum(nil)
+}
+
+// Hash returns the digest of s formatted so that it fits in the column.
+// Integer columns receive a number within their range, binary columns the
+// raw digest and character columns the encoded digest.
+func (r *HashRule) Hash(column *Column, s string) (interface{}, error) {
+	sum := r.digest(s)
+	switch {
+	case column.IsInteger():
+		return tokenValue(column, sum)
+	case column.IsBinary():
+		if column.MaxLength.Valid && int64(len(sum)) > column.MaxLength.Int64 {
+			sum = sum[:column.MaxLength.Int64]
+		}
+		return types.BinaryLiteral(sum), nil
+	case column.IsText():
+		if r.Encoding == "base64" {
+			return column.Truncate(base64.StdEncoding.EncodeToString(sum)), nil
+		}
+		return column.Truncate(hex.EncodeToString(sum)), nil
+	default:
+		return nil, fmt.Errorf("cannot hash column %s with type %s", column.Name, column.Type)
+	}
+}
+
+func (r *HashRule) Apply(row *Row) error {
+	for _, columnName := range r.Columns {
+		column, ok := row.Table.Columns[columnName]
+		if !ok {
+			continue
+		}
+
+		datum, ok := row.Datum(column)
+		if !ok || datum.Kind() == types.KindNull {
+			continue
+		}
+		s, err := datum.ToString()
+		if err != nil {
+			return err
+		}
+		value, err := r.Hash(column, s)
+		if err != nil {
+			return err
+		}
+		row.SetValue(column, value)
+	}
+	return nil
+}
+
+func (r *HashRule) CheckColumn(column *Column) error {
+	if !column.IsInteger() && !column.IsBinary() && !column.IsText() {
+		return fmt.Errorf("only integer, binary and character columns can be hashed")
+	}
+	return nil
+}
+
+func init() {
+	RegisterRule("hash", hashRuleDefaultSpec, func(value cty.Value, block *hcl.Block, table *Table) (Rule, error) {
+		return NewHashRule(value)
+	})
+}
+
+func NewHashRule(value cty.Value) (*HashRule, error) {
+	rule := &HashRule{}
+	err := gocty.FromCtyValue(value, &rule)
+	if err == nil {
+		err = rule.validate()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *HashRule) validate() error {
+	if _, ok := hashAlgorithms[r.Algorithm]; !ok {
+		return fmt.Errorf("algorithm must be one of sha256, sha1, md5 or xxhash")
+	}
+	switch r.Encoding {
+	case "hex", "base64":
+	default:
+		return fmt.Errorf("encoding must be hex or base64")
+	}
+	return nil
+}
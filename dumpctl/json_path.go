@@ -0,0 +1,342 @@
+package dumpctl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/types"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+type jsonPathStep struct {
+	Key      string
+	Index    int
+	IsIndex  bool
+	Wildcard bool
+}
+
+// jsonPath is a parsed MySQL-style JSON path such as $.contacts[*].email.
+type jsonPath []jsonPathStep
+
+func parseJSONPath(s string) (jsonPath, error) {
+	if !strings.HasPrefix(s, "$") {
+		return nil, fmt.Errorf("json path %q must start with $", s)
+	}
+	var path jsonPath
+	rest := s[1:]
+	for len(rest) > 0 {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			rest = rest[end:]
+			switch {
+			case key == "*":
+				path = append(path, jsonPathStep{Wildcard: true})
+			case len(key) == 0:
+				return nil, fmt.Errorf("json path %q has an empty key", s)
+			default:
+				path = append(path, jsonPathStep{Key: key})
+			}
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("json path %q has an unterminated [", s)
+			}
+			selector := rest[1:end]
+			rest = rest[end+1:]
+			switch {
+			case selector == "*":
+				path = append(path, jsonPathStep{Wildcard: true})
+			case len(selector) > 1 && (selector[0] == '"' || selector[0] == '\'') && selector[len(selector)-1] == selector[0]:
+				path = append(path, jsonPathStep{Key: selector[1 : len(selector)-1]})
+			default:
+				index, err := strconv.Atoi(selector)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("json path %q has an invalid array index %q", s, selector)
+				}
+				path = append(path, jsonPathStep{Index: index, IsIndex: true})
+			}
+		default:
+			return nil, fmt.Errorf("json path %q is invalid at %q", s, rest)
+		}
+	}
+	return path, nil
+}
+
+// Replace calls replace for every scalar matched by the path, or contained in
+// an object or array matched by the path, and stores the returned value in
+// its place.
+func (p jsonPath) Replace(doc interface{}, replace func(interface{}) (interface{}, error)) (interface{}, error) {
+	if len(p) == 0 {
+		return replaceScalars(doc, replace)
+	}
+	step, rest := p[0], p[1:]
+	var err error
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		if step.IsIndex {
+			return doc, nil
+		}
+		for key, value := range node {
+			if step.Wildcard || key == step.Key {
+				if node[key], err = rest.Replace(value, replace); err != nil {
+					return nil, err
+				}
+			}
+		}
+	case []interface{}:
+		for i, value := range node {
+			if step.Wildcard || (step.IsIndex && i == step.Index) {
+				if node[i], err = rest.Replace(value, replace); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return doc, nil
+}
+
+func replaceScalars(doc interface{}, replace func(interface{}) (interface{}, error)) (interface{}, error) {
+	var err error
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		for key, value := range node {
+			if node[key], err = replaceScalars(value, replace); err != nil {
+				return nil, err
+			}
+		}
+		return node, nil
+	case []interface{}:
+		for i, value := range node {
+			if node[i], err = replaceScalars(value, replace); err != nil {
+				return nil, err
+			}
+		}
+		return node, nil
+	case nil:
+		return nil, nil
+	default:
+		return replace(node)
+	}
+}
+
+// JSONPathRule applies another rule to values inside of JSON documents.
+type JSONPathRule struct {
+	Rule    Rule
+	Columns []string
+	Paths   []jsonPath
+	// scalarColumns hold the columns that stand in for the documents while
+	// the wrapped rule is applied to a scalar, by column name and type.
+	scalarColumns map[scalarColumnKey]*Column
+}
+
+type scalarColumnKey struct {
+	Column     string
+	ColumnType string
+}
+
+func NewJSONPathRule(rule Rule, body hcl.Body, pathsAttr *hcl.Attribute, ctx *hcl.EvalContext) (*JSONPathRule, hcl.Diagnostics) {
+	jsonRule := &JSONPathRule{Rule: rule}
+
+	content, _, diags := body.PartialContent(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "columns", Required: true}},
+	})
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	columnsValue, moreDiags := content.Attributes["columns"].Expr.Value(ctx)
+	if diags = append(diags, moreDiags...); moreDiags.HasErrors() {
+		return nil, diags
+	}
+	pathsValue, moreDiags := pathsAttr.Expr.Value(ctx)
+	if diags = append(diags, moreDiags...); moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	var paths []string
+	columnsValue, err := convert.Convert(columnsValue, cty.List(cty.String))
+	if err == nil {
+		err = gocty.FromCtyValue(columnsValue, &jsonRule.Columns)
+	}
+	if err == nil {
+		pathsValue, err = convert.Convert(pathsValue, cty.List(cty.String))
+	}
+	if err == nil {
+		err = gocty.FromCtyValue(pathsValue, &paths)
+	}
+	for _, s := range paths {
+		if err != nil {
+			break
+		}
+		var path jsonPath
+		path, err = parseJSONPath(s)
+		jsonRule.Paths = append(jsonRule.Paths, path)
+	}
+	if err != nil {
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("invalid paths: %v", err.Error()),
+			Subject:  pathsAttr.Expr.Range().Ptr(),
+		})
+	}
+	return jsonRule, diags
+}
+
+func (r *JSONPathRule) Apply(row *Row) error {
+	return r.transform(row, r.Rule.Apply)
+}
+
+// Reverse undoes the wrapped rule inside of the documents, which must be a
+// ReversibleRule.
+func (r *JSONPathRule) Reverse(row *Row) error {
+	rule, ok := r.Rule.(ReversibleRule)
+	if !ok {
+		return fmt.Errorf("the rule applied to the paths of %s cannot be reversed", strings.Join(r.Columns, ", "))
+	}
+	return r.transform(row, rule.Reverse)
+}
+
+// transform calls apply for every scalar matched by the paths, with a copy
+// of the row holding the scalar in place of the document.
+func (r *JSONPathRule) transform(row *Row, apply func(*Row) error) error {
+	for _, columnName := range r.Columns {
+		column, ok := row.Table.Columns[columnName]
+		if !ok {
+			continue
+		}
+
+		datum, ok := row.Datum(column)
+		if !ok || datum.Kind() == types.KindNull {
+			continue
+		}
+		s, err := datum.ToString()
+		if err != nil {
+			return err
+		}
+		decoder := json.NewDecoder(strings.NewReader(s))
+		decoder.UseNumber()
+		var doc interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			return fmt.Errorf("column %s does not contain valid JSON: %w", column.Name, err)
+		}
+
+		for _, path := range r.Paths {
+			doc, err = path.Replace(doc, func(value interface{}) (interface{}, error) {
+				return r.applyToScalar(row, column, value, apply)
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(doc); err != nil {
+			return err
+		}
+		row.SetValue(column, strings.TrimSuffix(buf.String(), "\n"))
+	}
+	return nil
+}
+
+// scalarColumn returns a copy of column with the given type, in a copy of its
+// table. The copies are made once for every column and type.
+func (r *JSONPathRule) scalarColumn(column *Column, dataType, columnType string) *Column {
+	key := scalarColumnKey{Column: column.Name, ColumnType: columnType}
+	if scalarColumn, ok := r.scalarColumns[key]; ok {
+		return scalarColumn
+	}
+
+	scalarColumn := *column
+	scalarColumn.Type, scalarColumn.ColumnType = dataType, columnType
+	scalarColumn.MaxLength.Valid = false
+
+	table := *column.Table
+	table.Columns = make(map[string]*Column, len(column.Table.Columns))
+	for name, c := range column.Table.Columns {
+		table.Columns[name] = c
+	}
+	table.Columns[column.Name] = &scalarColumn
+	scalarColumn.Table = &table
+
+	if r.scalarColumns == nil {
+		r.scalarColumns = make(map[scalarColumnKey]*Column)
+	}
+	r.scalarColumns[key] = &scalarColumn
+	return &scalarColumn
+}
+
+// applyToScalar runs apply against a copy of the row in which the JSON column
+// holds a single scalar from the document.
+func (r *JSONPathRule) applyToScalar(row *Row, column *Column, value interface{}, apply func(*Row) error) (interface{}, error) {
+	var scalarColumn *Column
+	var literal interface{}
+	switch v := value.(type) {
+	case string:
+		scalarColumn = r.scalarColumn(column, "varchar", "varchar")
+		literal = v
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			scalarColumn = r.scalarColumn(column, "bigint", "bigint")
+			literal = i
+		} else {
+			scalarColumn = r.scalarColumn(column, "double", "double")
+			f, err := v.Float64()
+			if err != nil {
+				return nil, err
+			}
+			literal = f
+		}
+	case bool:
+		scalarColumn = r.scalarColumn(column, "tinyint", "tinyint(1)")
+		if v {
+			literal = int64(1)
+		} else {
+			literal = int64(0)
+		}
+	default:
+		return value, nil
+	}
+
+	values := make([]ast.ExprNode, len(*row.Values))
+	copy(values, *row.Values)
+	scalarRow := &Row{Table: scalarColumn.Table, Values: &values}
+	scalarRow.SetValue(scalarColumn, literal)
+
+	if err := apply(scalarRow); err != nil {
+		return nil, err
+	}
+
+	datum, ok := scalarRow.Datum(scalarColumn)
+	if !ok {
+		return nil, fmt.Errorf("the rule applied to the paths of %s wrote DEFAULT, which has no value inside of a JSON document", column.Name)
+	}
+	switch datum.Kind() {
+	case types.KindNull:
+		return nil, nil
+	case types.KindString, types.KindBytes:
+		return datum.GetString(), nil
+	default:
+		s, err := datum.ToString()
+		if err != nil {
+			return nil, err
+		}
+		if _, isBool := value.(bool); isBool {
+			return s != "0", nil
+		}
+		return json.Number(s), nil
+	}
+}
@@ -0,0 +1,115 @@
+package dumpctl
+
+import (
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/pingcap/tidb/parser/ast"
+)
+
+func TestParseJSONPath(t *testing.T) {
+	tests := []struct {
+		s    string
+		want jsonPath
+	}{
+		{"$", nil},
+		{"$.email", jsonPath{{Key: "email"}}},
+		{"$.contacts[*].email", jsonPath{{Key: "contacts"}, {Wildcard: true}, {Key: "email"}}},
+		{"$.*", jsonPath{{Wildcard: true}}},
+		{"$[2]", jsonPath{{Index: 2, IsIndex: true}}},
+		{`$["first name"].x`, jsonPath{{Key: "first name"}, {Key: "x"}}},
+		{"$['a.b']", jsonPath{{Key: "a.b"}}},
+	}
+	for _, test := range tests {
+		got, err := parseJSONPath(test.s)
+		if err != nil {
+			t.Errorf("parseJSONPath(%q): %v", test.s, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseJSONPath(%q) = %+v, want %+v", test.s, got, test.want)
+		}
+	}
+
+	for _, s := range []string{"", "email", "$.", "$..a", "$[", "$[-1]", "$[a]", "$x"} {
+		if _, err := parseJSONPath(s); err == nil {
+			t.Errorf("parseJSONPath(%q) did not fail", s)
+		}
+	}
+}
+
+func TestJSONPathReplace(t *testing.T) {
+	tests := []struct {
+		path, doc, want string
+	}{
+		{"$.email", `{"email":"a","name":"b"}`, `{"email":"x","name":"b"}`},
+		{"$.contacts[*].email", `{"contacts":[{"email":"a"},{"email":"b","phone":"c"}]}`, `{"contacts":[{"email":"x"},{"email":"x","phone":"c"}]}`},
+		{"$[1]", `["a","b","c"]`, `["a","x","c"]`},
+		{"$.address", `{"address":{"city":"a","zip":["b"]},"id":1}`, `{"address":{"city":"x","zip":["x"]},"id":1}`},
+		{"$.missing", `{"email":"a"}`, `{"email":"a"}`},
+		{"$.email", `{"email":null}`, `{"email":null}`},
+	}
+	for _, test := range tests {
+		path, err := parseJSONPath(test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var doc interface{}
+		if err := json.Unmarshal([]byte(test.doc), &doc); err != nil {
+			t.Fatal(err)
+		}
+		doc, err = path.Replace(doc, func(interface{}) (interface{}, error) {
+			return "x", nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := json.Marshal(doc)
+		if string(got) != test.want {
+			t.Errorf("replacing %s in %s = %s, want %s", test.path, test.doc, got, test.want)
+		}
+	}
+}
+
+func TestJSONPathRuleRedact(t *testing.T) {
+	path, err := parseJSONPath("$.phone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		mode, want string
+	}{
+		{"empty", `{"name":"Ryder","phone":""}`},
+		{"null", `{"name":"Ryder","phone":null}`},
+		{"default", ""},
+	}
+	for _, test := range tests {
+		table := &Table{Name: "people", Columns: map[string]*Column{}}
+		column := &Column{Name: "profile", Position: 1, Type: "json", ColumnType: "json", Nullable: true, Default: sql.NullString{String: "{}", Valid: true}, Table: table}
+		table.Columns[column.Name] = column
+		rule := &JSONPathRule{
+			Rule:    &RedactRule{Columns: []string{"profile"}, Mode: test.mode},
+			Columns: []string{"profile"},
+			Paths:   []jsonPath{path},
+		}
+		values := []ast.ExprNode{ast.NewValueExpr(`{"name": "Ryder", "phone": "555-1234"}`, "", "")}
+		row := &Row{Table: table, Values: &values}
+		err := rule.Apply(row)
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("redacting paths with mode %s did not fail", test.mode)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("redacting paths with mode %s: %v", test.mode, err)
+			continue
+		}
+		datum, _ := row.Datum(column)
+		if got := datum.GetString(); got != test.want {
+			t.Errorf("redacting paths with mode %s = %s, want %s", test.mode, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package dumpctl
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/types"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// KAnonymity makes sure that every combination of the values of a table's
+// quasi-identifiers appears in at least K of its dumped rows. Rows of smaller
+// classes are generalized with the `generalize` blocks of the table, in
+// order, and the rows that are still in classes smaller than K are left out
+// of the dump.
+type KAnonymity struct {
+	Columns    []string
+	K          int
+	Generalize []*BucketRule
+}
+
+// NewKAnonymity reads the k-anonymity settings of a table. It returns nil if
+// the table has no quasi-identifiers.
+func NewKAnonymity(t *Table) (k *KAnonymity, diags hcl.Diagnostics) {
+	generalizeBlocks := t.BodyContent.Blocks.OfType("generalize")
+	if t.QuasiIdentifiersAttr == nil {
+		if t.K != 0 || len(generalizeBlocks) > 0 {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("%s needs quasi_identifiers for k-anonymity", t),
+				Subject:  t.Body.MissingItemRange().Ptr(),
+			})
+		}
+		return nil, diags
+	}
+
+	attr := t.QuasiIdentifiersAttr
+	if t.K < 2 {
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "k must be at least 2 when quasi_identifiers are given",
+			Subject:  attr.Range.Ptr(),
+		})
+	}
+	k = &KAnonymity{K: t.K}
+	ctx := t.EvalContext(false)
+	moreDiags := columnNames(attr.Expr, ctx, &k.Columns)
+	if diags = append(diags, moreDiags...); moreDiags.HasErrors() {
+		return nil, diags
+	}
+	quasiIdentifiers := make(map[string]bool)
+	for _, name := range k.Columns {
+		if _, ok := t.Columns[name]; !ok {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("%s has no column named %s", t, name),
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+		}
+		quasiIdentifiers[name] = true
+	}
+
+	for _, block := range generalizeBlocks {
+		value, moreDiags := hcldec.Decode(block.Body, bucketRuleDefaultSpec, ctx)
+		if diags = append(diags, moreDiags...); moreDiags.HasErrors() {
+			continue
+		}
+		rule, err := NewBucketRule(value)
+		if err != nil {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("error while configuring generalize block: %v", err),
+				Subject:  block.DefRange.Ptr(),
+			})
+			continue
+		}
+		moreDiags = t.checkColumns("generalize", rule, block.Body, t.EvalContext(false), true, false)
+		if diags = append(diags, moreDiags...); moreDiags.HasErrors() {
+			continue
+		}
+		for _, name := range rule.Columns {
+			if !quasiIdentifiers[name] {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  fmt.Sprintf("generalize block names %s, which is not a quasi-identifier", name),
+					Subject:  block.DefRange.Ptr(),
+				})
+			}
+		}
+		k.Generalize = append(k.Generalize, rule)
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return k, diags
+}
+
+// columnNames evaluates a list of column names, such as [zip, gender].
+func columnNames(expr hcl.Expression, ctx *hcl.EvalContext, columns *[]string) hcl.Diagnostics {
+	exprs, diags := hcl.ExprList(expr)
+	if diags.HasErrors() {
+		return diags
+	}
+	for _, expr := range exprs {
+		value, moreDiags := expr.Value(ctx)
+		if diags = append(diags, moreDiags...); moreDiags.HasErrors() {
+			continue
+		}
+		if value.IsNull() || value.Type() != cty.String {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "expected a column name",
+				Subject:  expr.Range().Ptr(),
+			})
+			continue
+		}
+		*columns = append(*columns, value.AsString())
+	}
+	return diags
+}
+
+// classKey returns the combination of the row's quasi-identifiers.
+func (k *KAnonymity) classKey(row *Row) (string, error) {
+	parts := make([]string, len(k.Columns))
+	for i, name := range k.Columns {
+		datum, ok := row.Datum(row.Table.Columns[name])
+		if !ok || datum.Kind() == types.KindNull {
+			parts[i] = "NULL"
+			continue
+		}
+		s, err := datum.ToString()
+		if err != nil {
+			return "", err
+		}
+		parts[i] = "'" + s
+	}
+	return strings.Join(parts, "\x00"), nil
+}
+
+// smallClasses returns the rows that are in classes of fewer than K rows.
+func (k *KAnonymity) smallClasses(rows []*Row) ([]*Row, error) {
+	classes := make(map[string][]*Row)
+	var keys []string
+	for _, row := range rows {
+		key, err := k.classKey(row)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := classes[key]; !ok {
+			keys = append(keys, key)
+		}
+		classes[key] = append(classes[key], row)
+	}
+	var small []*Row
+	for _, key := range keys {
+		if len(classes[key]) < k.K {
+			small = append(small, classes[key]...)
+		}
+	}
+	return small, nil
+}
+
+func (k *KAnonymity) generalize(rule *BucketRule, row *Row) error {
+	original := append([]ast.ExprNode{}, *row.Values...)
+	for _, name := range rule.Columns {
+		column := row.Table.Columns[name]
+		datum, ok := row.Datum(column)
+		if !ok || datum.Kind() == types.KindNull {
+			continue
+		}
+		value, err := rule.Bucket(column, datum)
+		if err != nil {
+			return fmt.Errorf("row %d of %s: %w", row.Number, row.Table, err)
+		}
+		row.SetValue(column, value)
+	}
+	return row.Validate(original)
+}
+
+// Enforce generalizes and suppresses rows until every class has at least K
+// rows and logs what it changed.
+func (k *KAnonymity) Enforce(table *Table, rows []*Row) error {
+	small, err := k.smallClasses(rows)
+	if err != nil {
+		return err
+	}
+	violations := len(small)
+
+	generalized := make(map[*Row]bool)
+	for _, rule := range k.Generalize {
+		if len(small) == 0 {
+			break
+		}
+		for _, row := range small {
+			if err := k.generalize(rule, row); err != nil {
+				return err
+			}
+			generalized[row] = true
+		}
+		if small, err = k.smallClasses(rows); err != nil {
+			return err
+		}
+	}
+
+	for _, row := range small {
+		row.Suppressed = true
+	}
+
+	log.Printf(
+		"k-anonymity of %s (k = %d over %s): %d of %d rows were in classes smaller than k, %d were generalized and %d were suppressed\n",
+		table, k.K, strings.Join(k.Columns, ", "), violations, len(rows), len(generalized), len(small),
+	)
+	return nil
+}
@@ -0,0 +1,188 @@
+package dumpctl
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/pingcap/tidb/types"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+// LookupRule replaces values according to a mapping read from a file. Values
+// without a mapping are replaced with one of the candidates, picked by a hash
+// of the value, or with the default.
+type LookupRule struct {
+	Columns        []string `cty:"columns"`
+	File           *string  `cty:"file"`
+	Candidates     []string `cty:"candidates"`
+	CandidatesFile *string  `cty:"candidates_file"`
+	Default        *string  `cty:"default"`
+	Mapping        map[string]string
+}
+
+var lookupRuleDefaultSpec = hcldec.ObjectSpec{
+	"columns": ColumnSpec,
+	"file": &hcldec.AttrSpec{
+		Name: "file",
+		Type: cty.String,
+	},
+	"candidates": &hcldec.AttrSpec{
+		Name: "candidates",
+		Type: cty.List(cty.String),
+	},
+	"candidates_file": &hcldec.AttrSpec{
+		Name: "candidates_file",
+		Type: cty.String,
+	},
+	"default": &hcldec.AttrSpec{
+		Name: "default",
+		Type: cty.String,
+	},
+}
+
+// readMappingFile reads a mapping from a CSV file with two columns or from
+// an HCL file with an object of strings, e.g. { "Acme Corp" = "Initech" }.
+func readMappingFile(path string) (map[string]string, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if filepath.Ext(path) == ".hcl" {
+		expr, diags := hclsyntax.ParseExpression(contents, path, hcl.InitialPos)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		value, diags := expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		mapping := make(map[string]string)
+		value, err := convert.Convert(value, cty.Map(cty.String))
+		if err == nil {
+			err = gocty.FromCtyValue(value, &mapping)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s must contain an object of strings: %w", path, err)
+		}
+		return mapping, nil
+	}
+
+	reader := csv.NewReader(strings.NewReader(string(contents)))
+	reader.FieldsPerRecord = 2
+	mapping := make(map[string]string)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		mapping[record[0]] = record[1]
+	}
+	return mapping, nil
+}
+
+// readCandidatesFile reads one candidate per line, ignoring blank lines.
+func readCandidatesFile(path string) ([]string, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []string
+	for _, line := range strings.Split(string(contents), "\n") {
+		if line = strings.TrimSpace(line); len(line) > 0 {
+			candidates = append(candidates, line)
+		}
+	}
+	return candidates, nil
+}
+
+// Lookup returns the replacement for s and whether there is one.
+func (r *LookupRule) Lookup(s string) (string, bool) {
+	if replacement, ok := r.Mapping[s]; ok {
+		return replacement, true
+	}
+	if len(r.Candidates) > 0 {
+		return pick(seededRand("lookup", s), r.Candidates), true
+	}
+	if r.Default != nil {
+		return *r.Default, true
+	}
+	return "", false
+}
+
+func (r *LookupRule) Apply(row *Row) error {
+	for _, columnName := range r.Columns {
+		column, ok := row.Table.Columns[columnName]
+		if !ok {
+			continue
+		}
+
+		datum, ok := row.Datum(column)
+		if !ok || datum.Kind() == types.KindNull {
+			continue
+		}
+		s, err := datum.ToString()
+		if err != nil {
+			return err
+		}
+		if replacement, ok := r.Lookup(s); ok {
+			row.SetValue(column, column.Truncate(replacement))
+		}
+	}
+	return nil
+}
+
+func (r *LookupRule) CheckColumn(column *Column) error {
+	if !column.IsText() && column.Type != "enum" {
+		return fmt.Errorf("only character and enum columns can be looked up")
+	}
+	return nil
+}
+
+func init() {
+	RegisterRule("lookup", lookupRuleDefaultSpec, func(value cty.Value, block *hcl.Block, table *Table) (Rule, error) {
+		return NewLookupRule(value, table.Database.Config)
+	})
+}
+
+func NewLookupRule(value cty.Value, config *Config) (*LookupRule, error) {
+	rule := &LookupRule{}
+	err := gocty.FromCtyValue(value, &rule)
+	if err == nil {
+		err = rule.load(config)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *LookupRule) load(config *Config) (err error) {
+	if r.File == nil && len(r.Candidates) == 0 && r.CandidatesFile == nil && r.Default == nil {
+		return fmt.Errorf("one of file, candidates, candidates_file or default is required")
+	}
+	if r.File != nil {
+		if r.Mapping, err = readMappingFile(config.Path(*r.File)); err != nil {
+			return err
+		}
+	}
+	if r.CandidatesFile != nil {
+		candidates, err := readCandidatesFile(config.Path(*r.CandidatesFile))
+		if err != nil {
+			return err
+		}
+		r.Candidates = append(r.Candidates, candidates...)
+	}
+	return nil
+}
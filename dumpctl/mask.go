@@ -0,0 +1,166 @@
+package dumpctl
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/pingcap/tidb/types"
+	driver "github.com/pingcap/tidb/types/parser_driver"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+type MaskRule struct {
+	Columns        []string `cty:"columns"`
+	Surrogate      string   `cty:"surrogate"`
+	PatternString  string   `cty:"pattern"`
+	PreserveFormat bool     `cty:"preserve_format"`
+	KeepFirst      int      `cty:"keep_first"`
+	KeepLast       int      `cty:"keep_last"`
+	Pattern        *regexp.Regexp
+}
+
+var maskRuleDefaultSpec = hcldec.ObjectSpec{
+	"columns": ColumnSpec,
+	"surrogate": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "surrogate",
+			Type: cty.String,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.StringVal("*")},
+	},
+	"pattern": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "pattern",
+			Type: cty.String,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.StringVal(`[^\s]`)},
+	},
+	"preserve_format": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "preserve_format",
+			Type: cty.Bool,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.False},
+	},
+	"keep_first": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "keep_first",
+			Type: cty.Number,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.Zero},
+	},
+	"keep_last": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "keep_last",
+			Type: cty.Number,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.Zero},
+	},
+}
+
+// Mask replaces the characters of s matched by the pattern. Unless the format
+// is preserved or characters are kept, each match is replaced by a single
+// surrogate.
+func (r *MaskRule) Mask(s string) string {
+	if !r.PreserveFormat && r.KeepFirst == 0 && r.KeepLast == 0 {
+		return r.Pattern.ReplaceAllString(s, r.Surrogate)
+	}
+
+	runes := []rune(s)
+	maskable := make([]bool, len(runes))
+	total := 0
+	for _, match := range r.Pattern.FindAllStringIndex(s, -1) {
+		start := utf8.RuneCountInString(s[:match[0]])
+		end := start + utf8.RuneCountInString(s[match[0]:match[1]])
+		for i := start; i < end; i++ {
+			if !r.PreserveFormat || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) {
+				maskable[i] = true
+				total++
+			}
+		}
+	}
+
+	var sb strings.Builder
+	n := 0
+	for i, c := range runes {
+		if !maskable[i] {
+			sb.WriteRune(c)
+			continue
+		}
+		if n < r.KeepFirst || n >= total-r.KeepLast {
+			sb.WriteRune(c)
+		} else if r.PreserveFormat {
+			sb.WriteRune(randomLike(c))
+		} else {
+			sb.WriteString(r.Surrogate)
+		}
+		n++
+	}
+	return sb.String()
+}
+
+// randomLike returns a random character of the same class as c: a digit for
+// a digit and a letter of the same case for a letter.
+func randomLike(c rune) rune {
+	switch {
+	case unicode.IsDigit(c):
+		return rune('0' + rand.Intn(10))
+	case unicode.IsUpper(c):
+		return rune('A' + rand.Intn(26))
+	default:
+		return rune('a' + rand.Intn(26))
+	}
+}
+
+func (r *MaskRule) Apply(row *Row) error {
+	for _, columnName := range r.Columns {
+		column, ok := row.Table.Columns[columnName]
+		if !ok {
+			continue
+		}
+
+		currentValueExpr := (*row.Values)[column.Position-1]
+
+		if expr, ok := currentValueExpr.(*driver.ValueExpr); ok && expr.Datum.Kind() != types.KindNull {
+			s, _ := expr.Datum.ToString()
+			row.SetValue(column, column.Truncate(r.Mask(s)))
+		}
+	}
+	return nil
+}
+
+func (r *MaskRule) CheckColumn(column *Column) error {
+	if !column.IsText() {
+		return fmt.Errorf("only character columns can be masked")
+	}
+	return nil
+}
+
+func init() {
+	RegisterRule("mask", maskRuleDefaultSpec, func(value cty.Value, block *hcl.Block, table *Table) (Rule, error) {
+		return NewMaskRule(value)
+	})
+}
+
+func NewMaskRule(value cty.Value) (*MaskRule, error) {
+	rule := &MaskRule{}
+	err := gocty.FromCtyValue(value, &rule)
+	if err != nil {
+		return nil, err
+	}
+	if rule.KeepFirst < 0 || rule.KeepLast < 0 {
+		return nil, fmt.Errorf("keep_first and keep_last must not be negative")
+	}
+	rule.Pattern, err = regexp.Compile(rule.PatternString)
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
@@ -0,0 +1,39 @@
+package dumpctl
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		rule MaskRule
+		s    string
+		want string
+	}{
+		{MaskRule{Surrogate: "*", PatternString: `[^\s]`}, "John Doe", "**** ***"},
+		{MaskRule{Surrogate: "#", PatternString: `.+`}, "secret", "#"},
+		{MaskRule{Surrogate: "*", PatternString: `[^\s]`, KeepFirst: 1}, "secret", "s*****"},
+		{MaskRule{Surrogate: "*", PatternString: `\d`, KeepLast: 4}, "4111-1111-1111-1234", "****-****-****-1234"},
+		{MaskRule{Surrogate: "*", PatternString: `\d`, KeepFirst: 2, KeepLast: 2}, "ab123", "ab123"},
+		{MaskRule{Surrogate: "*", PatternString: `[^\s]`, KeepLast: 2}, "äöüß", "**üß"},
+		{MaskRule{Surrogate: "*", PatternString: `[^\s]`}, "", ""},
+	}
+	for _, test := range tests {
+		rule := test.rule
+		rule.Pattern = regexp.MustCompile(rule.PatternString)
+		if got := rule.Mask(test.s); got != test.want {
+			t.Errorf("Mask(%q) with %+v = %q, want %q", test.s, test.rule, got, test.want)
+		}
+	}
+}
+
+func TestMaskPreserveFormat(t *testing.T) {
+	rule := MaskRule{Surrogate: "*", Pattern: regexp.MustCompile(`[^\s]`), PreserveFormat: true, KeepLast: 2}
+	format := regexp.MustCompile(`^[A-Z]{2}[a-z] [0-9]{3}-[0-9]{2}89$`)
+	for i := 0; i < 100; i++ {
+		if got := rule.Mask("ABc 123-4589"); !format.MatchString(got) {
+			t.Fatalf("Mask did not preserve the format of ABc 123-4589: %q", got)
+		}
+	}
+}
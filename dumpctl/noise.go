@@ -0,0 +1,260 @@
+package dumpctl
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/pingcap/tidb/types"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+type NoiseRule struct {
+	Columns      []string `cty:"columns"`
+	Distribution string   `cty:"distribution"`
+	Scale        float64  `cty:"scale"`
+	Bound        *float64 `cty:"bound"`
+	Relative     bool     `cty:"relative"`
+	PreserveSign bool     `cty:"preserve_sign"`
+	Min          *float64 `cty:"min"`
+	Max          *float64 `cty:"max"`
+	Round        *int     `cty:"round"`
+}
+
+var noiseRuleDefaultSpec = hcldec.ObjectSpec{
+	"columns": ColumnSpec,
+	"distribution": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "distribution",
+			Type: cty.String,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.StringVal("uniform")},
+	},
+	"scale": &hcldec.AttrSpec{
+		Name:     "scale",
+		Type:     cty.Number,
+		Required: true,
+	},
+	"bound": &hcldec.AttrSpec{
+		Name: "bound",
+		Type: cty.Number,
+	},
+	"relative": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "relative",
+			Type: cty.Bool,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.False},
+	},
+	"preserve_sign": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "preserve_sign",
+			Type: cty.Bool,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.False},
+	},
+	"min": &hcldec.AttrSpec{
+		Name: "min",
+		Type: cty.Number,
+	},
+	"max": &hcldec.AttrSpec{
+		Name: "max",
+		Type: cty.Number,
+	},
+	"round": &hcldec.AttrSpec{
+		Name: "round",
+		Type: cty.Number,
+	},
+}
+
+// noise draws a random number from the rule's distribution, limited to the
+// bound. Uniform noise is bounded by its scale and Gaussian and Laplace noise
+// by three times their scale unless a bound is configured.
+func (r *NoiseRule) noise(scale float64) float64 {
+	var n, bound float64
+	switch r.Distribution {
+	case "gaussian":
+		n = rand.NormFloat64() * scale
+		bound = 3 * scale
+	case "laplace":
+		u := rand.Float64() - 0.5
+		n = -scale * math.Copysign(math.Log(1-2*math.Abs(u)), u)
+		bound = 3 * scale
+	default:
+		n = (2*rand.Float64() - 1) * scale
+		bound = scale
+	}
+	if r.Bound != nil {
+		bound = *r.Bound
+		if r.Relative {
+			bound *= scale / r.Scale
+		}
+	}
+	return math.Max(-bound, math.Min(bound, n))
+}
+
+// roundRat rounds x to places decimal places, rounding halves away from zero.
+func roundRat(x *big.Rat, places int) *big.Rat {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil)
+	n := new(big.Int).Mul(x.Num(), scale)
+	n.Mul(n, big.NewInt(2))
+	if n.Sign() < 0 {
+		n.Sub(n, x.Denom())
+	} else {
+		n.Add(n, x.Denom())
+	}
+	n.Quo(n, new(big.Int).Mul(x.Denom(), big.NewInt(2)))
+	return new(big.Rat).SetFrac(n, scale)
+}
+
+func clampRat(x, min, max *big.Rat) *big.Rat {
+	if x.Cmp(min) < 0 {
+		return min
+	}
+	if x.Cmp(max) > 0 {
+		return max
+	}
+	return x
+}
+
+// places returns the number of decimal places a value of the column is
+// written with, or -1 if it is not rounded.
+func (r *NoiseRule) places(column *Column, original types.Datum) int {
+	places := -1
+	switch {
+	case column.IsInteger():
+		places = 0
+	case column.Scale.Valid && (column.Type == "decimal" || column.Type == "numeric"):
+		places = int(column.Scale.Int64)
+	case original.Kind() == types.KindMysqlDecimal:
+		_, places = original.GetMysqlDecimal().PrecisionAndFrac()
+	}
+	if r.Round != nil && (places < 0 || *r.Round < places) {
+		places = *r.Round
+	}
+	return places
+}
+
+// Perturb adds noise to a single numeric value. The result is kept within
+// the range and precision of the column.
+func (r *NoiseRule) Perturb(column *Column, datum types.Datum) (interface{}, error) {
+	s, err := datum.ToString()
+	if err != nil {
+		return nil, err
+	}
+	value, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("cannot add noise to %s in column %s", s, column.Name)
+	}
+
+	scale := r.Scale
+	if r.Relative {
+		f, _ := value.Float64()
+		scale *= math.Abs(f)
+	}
+	result := new(big.Rat).Add(value, new(big.Rat).SetFloat64(r.noise(scale)))
+	if r.PreserveSign && result.Sign() != 0 && result.Sign() != value.Sign() {
+		result = new(big.Rat)
+	}
+	places := r.places(column, datum)
+	if places >= 0 {
+		result = roundRat(result, places)
+	}
+	if r.Min != nil && result.Cmp(new(big.Rat).SetFloat64(*r.Min)) < 0 {
+		result = new(big.Rat).SetFloat64(*r.Min)
+	}
+	if r.Max != nil && result.Cmp(new(big.Rat).SetFloat64(*r.Max)) > 0 {
+		result = new(big.Rat).SetFloat64(*r.Max)
+	}
+	min, max := column.NumericRange()
+	result = clampRat(result, min, max)
+
+	switch {
+	case column.IsInteger():
+		n := new(big.Int).Quo(result.Num(), result.Denom())
+		if n.Sign() < 0 {
+			return n.Int64(), nil
+		}
+		return n.Uint64(), nil
+	case places >= 0 && (column.Type == "decimal" || column.Type == "numeric" || datum.Kind() == types.KindMysqlDecimal):
+		dec := new(types.MyDecimal)
+		err := dec.FromString([]byte(result.FloatString(places)))
+		return dec, err
+	default:
+		f, _ := result.Float64()
+		return f, nil
+	}
+}
+
+func (r *NoiseRule) Apply(row *Row) error {
+	for _, columnName := range r.Columns {
+		column, ok := row.Table.Columns[columnName]
+		if !ok {
+			continue
+		}
+
+		datum, ok := row.Datum(column)
+		if !ok || datum.Kind() == types.KindNull {
+			continue
+		}
+		if !column.IsNumeric() {
+			return fmt.Errorf("cannot add noise to column %s with type %s", column.Name, column.Type)
+		}
+		value, err := r.Perturb(column, datum)
+		if err != nil {
+			return err
+		}
+		row.SetValue(column, value)
+	}
+	return nil
+}
+
+func (r *NoiseRule) CheckColumn(column *Column) error {
+	if !column.IsNumeric() {
+		return fmt.Errorf("only numeric columns can receive noise")
+	}
+	return nil
+}
+
+func init() {
+	RegisterRule("noise", noiseRuleDefaultSpec, func(value cty.Value, block *hcl.Block, table *Table) (Rule, error) {
+		return NewNoiseRule(value)
+	})
+}
+
+func NewNoiseRule(value cty.Value) (*NoiseRule, error) {
+	rule := &NoiseRule{}
+	err := gocty.FromCtyValue(value, &rule)
+	if err == nil {
+		err = rule.validate()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *NoiseRule) validate() error {
+	switch r.Distribution {
+	case "uniform", "gaussian", "laplace":
+	default:
+		return fmt.Errorf("distribution must be one of uniform, gaussian or laplace")
+	}
+	if r.Scale <= 0 {
+		return fmt.Errorf("scale must be greater than 0")
+	}
+	if r.Bound != nil && *r.Bound < 0 {
+		return fmt.Errorf("bound must not be negative")
+	}
+	if r.Round != nil && *r.Round < 0 {
+		return fmt.Errorf("round must not be negative")
+	}
+	if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+		return fmt.Errorf("min must not be greater than max")
+	}
+	return nil
+}
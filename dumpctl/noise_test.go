@@ -0,0 +1,132 @@
+package dumpctl
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/pingcap/tidb/types"
+)
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
+func noiseFloat(t *testing.T, value interface{}) float64 {
+	t.Helper()
+	switch v := value.(type) {
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float64:
+		return v
+	case *types.MyDecimal:
+		f, err := v.ToFloat64()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	t.Fatalf("unexpected value %#v", value)
+	return 0
+}
+
+func TestPerturb(t *testing.T) {
+	decimal := new(types.MyDecimal)
+	if err := decimal.FromString([]byte("100.00")); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		rule     NoiseRule
+		column   Column
+		datum    types.Datum
+		min, max float64
+	}{
+		{
+			"uniform",
+			NoiseRule{Scale: 5},
+			Column{Type: "int", ColumnType: "int"},
+			types.NewIntDatum(100),
+			95, 105,
+		},
+		{
+			"column range",
+			NoiseRule{Scale: 20},
+			Column{Type: "tinyint", ColumnType: "tinyint unsigned"},
+			types.NewUintDatum(250),
+			230, 255,
+		},
+		{
+			"gaussian",
+			NoiseRule{Distribution: "gaussian", Scale: 1},
+			Column{Type: "double", ColumnType: "double"},
+			types.NewFloat64Datum(0),
+			-3, 3,
+		},
+		{
+			"laplace with bound",
+			NoiseRule{Distribution: "laplace", Scale: 10, Bound: float64Ptr(2)},
+			Column{Type: "double", ColumnType: "double"},
+			types.NewFloat64Datum(0),
+			-2, 2,
+		},
+		{
+			"relative",
+			NoiseRule{Scale: 0.1, Relative: true},
+			Column{Type: "bigint", ColumnType: "bigint"},
+			types.NewIntDatum(-1000),
+			-1100, -900,
+		},
+		{
+			"preserve sign",
+			NoiseRule{Scale: 10, PreserveSign: true},
+			Column{Type: "int", ColumnType: "int"},
+			types.NewIntDatum(1),
+			0, 11,
+		},
+		{
+			"min and max",
+			NoiseRule{Scale: 100, Min: float64Ptr(0), Max: float64Ptr(10)},
+			Column{Type: "int", ColumnType: "int"},
+			types.NewIntDatum(5),
+			0, 10,
+		},
+		{
+			"decimal",
+			NoiseRule{Scale: 1},
+			Column{Type: "decimal", ColumnType: "decimal(5,2)", Precision: sql.NullInt64{Int64: 5, Valid: true}, Scale: sql.NullInt64{Int64: 2, Valid: true}},
+			types.NewDecimalDatum(decimal),
+			99, 101,
+		},
+	}
+	for _, test := range tests {
+		for i := 0; i < 200; i++ {
+			value, err := test.rule.Perturb(&test.column, test.datum)
+			if err != nil {
+				t.Fatalf("%s: %v", test.name, err)
+			}
+			if f := noiseFloat(t, value); f < test.min || f > test.max {
+				t.Fatalf("%s: %v is not between %v and %v", test.name, f, test.min, test.max)
+			}
+		}
+	}
+}
+
+func TestPerturbRound(t *testing.T) {
+	decimal := new(types.MyDecimal)
+	if err := decimal.FromString([]byte("12.345")); err != nil {
+		t.Fatal(err)
+	}
+	round := 1
+	rule := NoiseRule{Scale: 1, Round: &round}
+	column := &Column{Type: "decimal", ColumnType: "decimal(6,3)", Precision: sql.NullInt64{Int64: 6, Valid: true}, Scale: sql.NullInt64{Int64: 3, Valid: true}}
+	value, err := rule.Perturb(column, types.NewDecimalDatum(decimal))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, frac := value.(*types.MyDecimal).PrecisionAndFrac()
+	if frac != 1 {
+		t.Errorf("%s has %d decimal places, want 1", value.(*types.MyDecimal), frac)
+	}
+}
@@ -0,0 +1,146 @@
+package dumpctl
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Policy is a named group of rules that tables can share with
+// `policies = ["<name>"]`. Its rules are added to each table that uses it,
+// with their columns resolved against the table's schema.
+type Policy struct {
+	Name  string
+	Block *hcl.Block
+	Rules hcl.Blocks
+}
+
+var policySchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{
+			Type:       "rule",
+			LabelNames: []string{"name"},
+		},
+	},
+}
+
+func NewPolicy(name string, block *hcl.Block) (policy *Policy, diags hcl.Diagnostics) {
+	content, diags := block.Body.Content(policySchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return &Policy{
+		Name:  name,
+		Block: block,
+		Rules: content.Blocks.OfType("rule"),
+	}, diags
+}
+
+// PolicyNames returns the names of all policies of the config in order.
+func (c *Config) PolicyNames() []string {
+	names := make([]string, 0, len(c.Policies))
+	for name := range c.Policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ReadPolicies resolves the `policies` attribute of the table.
+func (t *Table) ReadPolicies() (policies []*Policy, diags hcl.Diagnostics) {
+	if t.PoliciesAttr == nil {
+		return
+	}
+	exprs, diags := hcl.ExprList(t.PoliciesAttr.Expr)
+	if diags.HasErrors() {
+		return
+	}
+	for _, expr := range exprs {
+		var name string
+		moreDiags := gohcl.DecodeExpression(expr, nil, &name)
+		if diags = append(diags, moreDiags...); moreDiags.HasErrors() {
+			continue
+		}
+		policy, ok := t.Database.Config.Policies[name]
+		if !ok {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("there is no policy named %s", name),
+				Detail:   fmt.Sprintf("The policies of the config are %s.", strings.Join(t.Database.Config.PolicyNames(), ", ")),
+				Subject:  expr.Range().Ptr(),
+			})
+			continue
+		}
+		policies = append(policies, policy)
+	}
+	return
+}
+
+// policyEvalContext returns the context for evaluating a rule of a policy.
+// Columns the table does not have evaluate to their own names, so that they
+// can be removed from the rule with policyColumns instead of failing.
+func (t *Table) policyEvalContext(body hcl.Body) *hcl.EvalContext {
+	ctx := t.EvalContext(false)
+	content, _, _ := body.PartialContent(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "columns"}},
+	})
+	attr, ok := content.Attributes["columns"]
+	if !ok {
+		return ctx
+	}
+	for _, traversal := range attr.Expr.Variables() {
+		name := traversal.RootName()
+		if _, ok := ctx.Variables[name]; !ok {
+			ctx.Variables[name] = cty.StringVal(name)
+		}
+	}
+	return ctx
+}
+
+// policyColumns removes the columns the table does not have from the decoded
+// attributes of a rule. ok is false if the rule has columns but none of them
+// are in the table, in which case the rule is not added to the table.
+func (t *Table) policyColumns(value cty.Value) (result cty.Value, ok bool) {
+	if value.IsNull() || !value.Type().IsObjectType() || !value.Type().HasAttribute("columns") {
+		return value, true
+	}
+	columns := value.GetAttr("columns")
+	if columns.IsNull() || !columns.IsWhollyKnown() || !columns.CanIterateElements() {
+		return value, true
+	}
+	var kept []cty.Value
+	for _, column := range columns.AsValueSlice() {
+		if column.Type() != cty.String || column.IsNull() {
+			continue
+		}
+		if _, ok := t.Columns[column.AsString()]; ok {
+			kept = append(kept, column)
+		}
+	}
+	if len(kept) == 0 {
+		return value, false
+	}
+	attrs := value.AsValueMap()
+	attrs["columns"] = cty.ListVal(kept)
+	return cty.ObjectVal(attrs), true
+}
+
+// AddPolicyRules adds the rules of a policy to the table. Rules whose columns
+// are all missing from the table are skipped.
+func (t *Table) AddPolicyRules(policy *Policy) (diags hcl.Diagnostics) {
+	for _, ruleBlock := range policy.Rules {
+		rule, moreDiags := t.addRule(ruleBlock.Labels[0], ruleBlock, true)
+		if diags = append(diags, moreDiags...); moreDiags.HasErrors() {
+			continue
+		}
+		if rule == nil && !t.Database.Config.Decrypting {
+			log.Printf("DEBUG: %s has none of the columns of the %s rule of policy %s\n", t, ruleBlock.Labels[0], policy.Name)
+		}
+	}
+	return
+}
@@ -0,0 +1,179 @@
+package dumpctl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/pingcap/tidb/types"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+type RedactRule struct {
+	Columns []string `cty:"columns"`
+	Mode    string   `cty:"mode"`
+}
+
+var redactRuleDefaultSpec = hcldec.ObjectSpec{
+	"columns": ColumnSpec,
+	"mode": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "mode",
+			Type: cty.String,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.StringVal("empty")},
+	},
+}
+
+// redactedTemporalValues are the values written to redacted temporal
+// columns. Zero dates are rejected by the default sql_mode of MySQL 5.7 and
+// later, so dates start at the epoch instead. Timestamps use the day after
+// so that they stay in range in every time zone.
+var redactedTemporalValues = map[string]string{
+	"date":      "1970-01-01",
+	"datetime":  "1970-01-01 00:00:00",
+	"timestamp": "1970-01-02 00:00:00",
+	"time":      "00:00:00",
+}
+
+// redactedValue returns the empty value of a column. The column's type is
+// considered before the datum's kind because mysqldump writes most values,
+// including dates, enums and JSON documents, as strings.
+func redactedValue(column *Column, datum types.Datum) (interface{}, error) {
+	switch column.Type {
+	case "enum":
+		if elements := column.Elements(); len(elements) > 0 {
+			return elements[0], nil
+		}
+		return "", nil
+	case "set":
+		return "", nil
+	case "json":
+		return redactedJSON(datum), nil
+	case "year":
+		return int64(0), nil
+	case "bit":
+		return make(types.BinaryLiteral, len(datum.GetBytes())), nil
+	}
+	if value, ok := redactedTemporalValues[column.Type]; ok {
+		return value, nil
+	}
+	if column.IsBinary() {
+		return types.BinaryLiteral{}, nil
+	}
+
+	switch datum.Kind() {
+	case types.KindInt64, types.KindUint64, types.KindFloat32, types.KindFloat64:
+		return 0, nil
+	case types.KindMysqlDecimal:
+		_, frac := datum.GetMysqlDecimal().PrecisionAndFrac()
+		dec := new(types.MyDecimal)
+		err := dec.FromString([]byte(fmt.Sprintf("%.*f", frac, 0.0)))
+		return dec, err
+	case types.KindString, types.KindBytes, types.KindMysqlSet:
+		return "", nil
+	case types.KindMysqlTime:
+		return redactedTemporalValues["datetime"], nil
+	case types.KindMysqlDuration:
+		return redactedTemporalValues["time"], nil
+	case types.KindMysqlEnum:
+		if elements := column.Elements(); len(elements) > 0 {
+			return elements[0], nil
+		}
+		return "", nil
+	case types.KindMysqlJSON:
+		return redactedJSON(datum), nil
+	case types.KindBinaryLiteral, types.KindMysqlBit:
+		return types.BinaryLiteral{}, nil
+	default:
+		return nil, fmt.Errorf("don't know how to redact column %s with kind %d", column.Name, datum.Kind())
+	}
+}
+
+// redactedJSON empties objects and replaces any other document with null.
+func redactedJSON(datum types.Datum) string {
+	s, _ := datum.ToString()
+	if strings.HasPrefix(strings.TrimSpace(s), "{") {
+		return "{}"
+	}
+	return "null"
+}
+
+// columnMode resolves the auto and default modes to null, default or empty
+// using the column's nullability and declared default.
+func (r *RedactRule) columnMode(column *Column) string {
+	switch {
+	case r.Mode != "auto" && r.Mode != "default":
+		return r.Mode
+	case r.Mode == "auto" && column.Nullable:
+		return "null"
+	case column.Default.Valid:
+		return "default"
+	case column.Nullable:
+		return "null"
+	default:
+		return "empty"
+	}
+}
+
+func (r *RedactRule) Apply(row *Row) error {
+	for _, columnName := range r.Columns {
+		column, ok := row.Table.Columns[columnName]
+		if !ok {
+			continue
+		}
+
+		datum, ok := row.Datum(column)
+		if !ok || datum.Kind() == types.KindNull {
+			continue
+		}
+
+		switch r.columnMode(column) {
+		case "null":
+			if !column.Nullable {
+				return fmt.Errorf("cannot redact NOT NULL column %s with NULL", column.Name)
+			}
+			row.SetValue(column, nil)
+		case "default":
+			row.SetDefault(column)
+		default:
+			value, err := redactedValue(column, datum)
+			if err != nil {
+				return err
+			}
+			row.SetValue(column, value)
+		}
+	}
+	return nil
+}
+
+func (r *RedactRule) CheckColumn(column *Column) error {
+	if r.columnMode(column) == "null" && !column.Nullable {
+		return fmt.Errorf("NOT NULL columns cannot be redacted with NULL")
+	}
+	return nil
+}
+
+func init() {
+	RegisterRule("redact", redactRuleDefaultSpec, func(value cty.Value, block *hcl.Block, table *Table) (Rule, error) {
+		return NewRedactRule(value)
+	})
+}
+
+func NewRedactRule(value cty.Value) (*RedactRule, error) {
+	rule := &RedactRule{}
+	err := gocty.FromCtyValue(value, &rule)
+	if err == nil {
+		switch rule.Mode {
+		case "null", "empty", "default", "auto":
+		default:
+			err = fmt.Errorf("mode must be one of null, empty, default or auto")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
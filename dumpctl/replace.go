@@ -0,0 +1,85 @@
+package dumpctl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/pingcap/tidb/types"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+type ReplaceRule struct {
+	Columns   []string `cty:"columns"`
+	Generator string   `cty:"generator"`
+	Locale    string   `cty:"locale"`
+}
+
+var replaceRuleDefaultSpec = hcldec.ObjectSpec{
+	"columns": ColumnSpec,
+	"generator": &hcldec.AttrSpec{
+		Name:     "generator",
+		Type:     cty.String,
+		Required: true,
+	},
+	"locale": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "locale",
+			Type: cty.String,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.StringVal("en")},
+	},
+}
+
+func (r *ReplaceRule) Apply(row *Row) error {
+	generate := fakerGenerators[r.Generator]
+	for _, columnName := range r.Columns {
+		column, ok := row.Table.Columns[columnName]
+		if !ok {
+			continue
+		}
+
+		datum, ok := row.Datum(column)
+		if !ok || datum.Kind() == types.KindNull {
+			continue
+		}
+		s, err := datum.ToString()
+		if err != nil {
+			return err
+		}
+		value := generate(seededRand(r.Generator, r.Locale, s), r.Locale)
+		row.SetValue(column, column.Truncate(value))
+	}
+	return nil
+}
+
+func (r *ReplaceRule) CheckColumn(column *Column) error {
+	if !column.IsText() {
+		return fmt.Errorf("only character columns can be replaced")
+	}
+	return nil
+}
+
+func init() {
+	RegisterRule("replace", replaceRuleDefaultSpec, func(value cty.Value, block *hcl.Block, table *Table) (Rule, error) {
+		return NewReplaceRule(value)
+	})
+}
+
+func NewReplaceRule(value cty.Value) (*ReplaceRule, error) {
+	rule := &ReplaceRule{}
+	err := gocty.FromCtyValue(value, &rule)
+	if err == nil {
+		if _, ok := fakerGenerators[rule.Generator]; !ok {
+			err = fmt.Errorf("unknown generator %q, expected one of %s", rule.Generator, strings.Join(fakerGeneratorNames(), ", "))
+		} else if _, ok := fakerLocales[rule.Locale]; !ok {
+			err = fmt.Errorf("unknown locale %q, expected one of %s", rule.Locale, strings.Join(fakerLocaleNames(), ", "))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
@@ -1,4 +1,4 @@
-package main
+package dumpctl
 
 import (
 	"bufio"
@@ -17,23 +17,30 @@ import (
 
 type RuleVisitor struct {
 	Table *Table
+	Rows  int64
 }
 
 func (v *RuleVisitor) Enter(in ast.Node) (ast.Node, bool) {
 	if stmt, ok := in.(*ast.InsertStmt); ok {
 		valuesExpr := stmt.Lists[0]
+		original := append([]ast.ExprNode{}, valuesExpr...)
+		v.Rows++
 
 		row := &Row{
 			Table:  v.Table,
 			Values: &valuesExpr,
+			Number: v.Rows,
 		}
 		for _, rule := range v.Table.Rules {
 			err := rule.Apply(row)
 			if err != nil {
 				// @TODO continue and collect errors?
-				log.Fatal(err.Error())
+				log.Fatalf("row %d of %s: %s", row.Number, v.Table, err.Error())
 			}
 		}
+		if err := row.Validate(original); err != nil {
+			log.Fatal(err.Error())
+		}
 	}
 	return in, true
 }
@@ -42,6 +49,23 @@ func (v *RuleVisitor) Leave(in ast.Node) (ast.Node, bool) {
 	return in, true
 }
 
+// bufferedLine is a line of a dump that is held back until a table has been
+// read completely. INSERT statements are kept parsed, along with their row,
+// so that buffered rules can still change their values.
+type bufferedLine struct {
+	Line string
+	Stmt ast.StmtNode
+	Row  *Row
+}
+
+func writeStatement(w io.Writer, stmtNode ast.StmtNode) {
+	err := stmtNode.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, w))
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+	w.Write([]byte(";\n"))
+}
+
 type Rewriter struct {
 	Database    *Database
 	Dumper      *Dumper
@@ -90,6 +114,12 @@ func (r *Rewriter) Visit(wrapper dag.Vertexer) {
 		r.Dumper.Dump(writePipe)
 	}()
 
+	// Tables with buffered rules or k-anonymity are held in memory until all
+	// of their rows have been read, the rules have been flushed and
+	// k-anonymity has been enforced.
+	buffering := len(table.BufferedRules) > 0 || table.KAnonymity != nil
+	var buffer []bufferedLine
+
 	scanner := bufio.NewScanner(readPipe)
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -101,11 +131,18 @@ func (r *Rewriter) Visit(wrapper dag.Vertexer) {
 			}
 
 			stmtNode.Accept(visitor)
-			err = stmtNode.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, table.OutFile))
-			if err != nil {
-				log.Fatalf(err.Error())
+			if buffering {
+				row := &Row{
+					Table:  table,
+					Values: &stmtNode.(*ast.InsertStmt).Lists[0],
+					Number: visitor.Rows,
+				}
+				buffer = append(buffer, bufferedLine{Stmt: stmtNode, Row: row})
+				continue
 			}
-			table.OutFile.Write([]byte(";\n"))
+			writeStatement(table.OutFile, stmtNode)
+		} else if buffering {
+			buffer = append(buffer, bufferedLine{Line: line})
 		} else {
 			table.OutFile.WriteString(fmt.Sprintf("%s\n", line))
 		}
@@ -113,6 +150,32 @@ func (r *Rewriter) Visit(wrapper dag.Vertexer) {
 	if err := scanner.Err(); err != nil {
 		log.Fatal(err.Error())
 	}
+	for _, rule := range table.BufferedRules {
+		if err := rule.Flush(); err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+	if table.KAnonymity != nil {
+		var rows []*Row
+		for _, buffered := range buffer {
+			if buffered.Row != nil {
+				rows = append(rows, buffered.Row)
+			}
+		}
+		if err := table.KAnonymity.Enforce(table, rows); err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+	for _, buffered := range buffer {
+		if buffered.Row != nil && buffered.Row.Suppressed {
+			continue
+		}
+		if buffered.Stmt != nil {
+			writeStatement(table.OutFile, buffered.Stmt)
+		} else {
+			table.OutFile.WriteString(fmt.Sprintf("%s\n", buffered.Line))
+		}
+	}
 	table.OutFile.Seek(0, io.SeekStart)
 	io.Copy(os.Stdout, table.OutFile)
 	table.Dumped = true
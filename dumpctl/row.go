@@ -0,0 +1,157 @@
+package dumpctl
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/types"
+	driver "github.com/pingcap/tidb/types/parser_driver"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Datum returns the literal value of a column in the row. mysqldump writes
+// negative numbers as a unary minus applied to a literal, so those are folded
+// into a single negative datum here.
+func (r *Row) Datum(column *Column) (types.Datum, bool) {
+	switch expr := (*r.Values)[column.Position-1].(type) {
+	case *driver.ValueExpr:
+		return expr.Datum, true
+	case *ast.UnaryOperationExpr:
+		value, ok := expr.V.(*driver.ValueExpr)
+		if !ok || expr.Op != opcode.Minus {
+			return types.Datum{}, false
+		}
+		switch value.Kind() {
+		case types.KindInt64:
+			return types.NewIntDatum(-value.GetInt64()), true
+		case types.KindUint64:
+			if value.GetUint64() > math.MaxInt64 {
+				return types.NewFloat64Datum(-float64(value.GetUint64())), true
+			}
+			return types.NewIntDatum(-int64(value.GetUint64())), true
+		case types.KindFloat32, types.KindFloat64:
+			return types.NewFloat64Datum(-value.GetFloat64()), true
+		case types.KindMysqlDecimal:
+			return types.NewDecimalDatum(types.DecimalNeg(value.GetMysqlDecimal())), true
+		}
+	}
+	return types.Datum{}, false
+}
+
+// SetValue replaces the value of a column in the row with a new literal.
+// Binary literals are written as hex literals like mysqldump --hex-blob does.
+func (r *Row) SetValue(column *Column, value interface{}) {
+	expr := ast.NewValueExpr(value, "", "")
+	if _, ok := value.(types.BinaryLiteral); ok {
+		expr.GetType().AddFlag(mysql.UnsignedFlag)
+	}
+	(*r.Values)[column.Position-1] = expr
+}
+
+// SetDefault replaces the value of a column in the row with the DEFAULT
+// keyword so that the column's declared default is used on import.
+func (r *Row) SetDefault(column *Column) {
+	(*r.Values)[column.Position-1] = &ast.DefaultExpr{}
+}
+
+// Validate checks the values of the row that differ from original, the values
+// the row was read with, against the schema of their columns. Values that a
+// rule wrote back unchanged are not checked, so that invalid values of the
+// source, such as zero dates, do not stop the dump.
+func (r *Row) Validate(original []ast.ExprNode) error {
+	before := &Row{Table: r.Table, Values: &original}
+	var changed []*Column
+	for _, column := range r.Table.Columns {
+		if (*r.Values)[column.Position-1] == original[column.Position-1] {
+			continue
+		}
+		if sameDatum(r, before, column) {
+			continue
+		}
+		changed = append(changed, column)
+	}
+	sort.Slice(changed, func(i, j int) bool {
+		return changed[i].Position < changed[j].Position
+	})
+	for _, column := range changed {
+		datum, ok := r.Datum(column)
+		if !ok {
+			continue
+		}
+		if err := column.Validate(datum); err != nil {
+			return fmt.Errorf("row %d of %s, column %s: %w", r.Number, r.Table, column.Name, err)
+		}
+	}
+	return nil
+}
+
+// sameDatum reports whether a column has the same literal value in both rows.
+func sameDatum(a, b *Row, column *Column) bool {
+	x, ok := a.Datum(column)
+	if !ok {
+		return false
+	}
+	y, ok := b.Datum(column)
+	if !ok || x.Kind() != y.Kind() {
+		return false
+	}
+	if x.Kind() == types.KindNull {
+		return true
+	}
+	xs, err := x.ToString()
+	if err != nil {
+		return false
+	}
+	ys, err := y.ToString()
+	return err == nil && xs == ys
+}
+
+// CtyValue returns the value of a column in the row for use in HCL
+// expressions. Numeric columns become numbers and all others strings.
+func (r *Row) CtyValue(column *Column) cty.Value {
+	valueType := cty.String
+	if column.IsNumeric() || column.Type == "year" {
+		valueType = cty.Number
+	}
+	datum, ok := r.Datum(column)
+	if !ok || datum.Kind() == types.KindNull {
+		return cty.NullVal(valueType)
+	}
+	switch datum.Kind() {
+	case types.KindInt64:
+		return cty.NumberIntVal(datum.GetInt64())
+	case types.KindUint64:
+		return cty.NumberUIntVal(datum.GetUint64())
+	case types.KindFloat32, types.KindFloat64:
+		return cty.NumberFloatVal(datum.GetFloat64())
+	}
+	s, err := datum.ToString()
+	if err != nil {
+		return cty.NullVal(valueType)
+	}
+	if valueType == cty.Number {
+		if value, err := cty.ParseNumberVal(s); err == nil {
+			return value
+		}
+	}
+	return cty.StringVal(s)
+}
+
+// EvalContext returns the table's evaluation context with the values of the
+// row available as attributes of the `row` variable. `row` is the only
+// variable, so that a bare column name cannot evaluate to the name itself.
+func (r *Row) EvalContext() *hcl.EvalContext {
+	values := make(map[string]cty.Value, len(r.Table.Columns))
+	for name, column := range r.Table.Columns {
+		values[name] = r.CtyValue(column)
+	}
+	ctx := r.Table.EvalContext(false)
+	ctx.Variables = map[string]cty.Value{"row": cty.ObjectVal(values)}
+	return ctx
+}
@@ -0,0 +1,89 @@
+package dumpctl
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldec"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ColumnSpec is the spec of the required `columns` attribute of rules that
+// change a list of columns.
+var ColumnSpec = &hcldec.AttrSpec{
+	Name:     "columns",
+	Type:     cty.List(cty.String),
+	Required: true,
+}
+
+type Rule interface {
+	Apply(*Row) error
+}
+
+// A RuleConstructor creates a rule from a rule block. value holds the
+// attributes of the block decoded with the spec the rule type was registered
+// with. Rule types registered without a spec receive a null value and decode
+// the block themselves. Errors of type hcl.Diagnostics are reported as they
+// are, all others as an error in the rule block.
+type RuleConstructor func(value cty.Value, block *hcl.Block, table *Table) (Rule, error)
+
+type ruleType struct {
+	Spec        hcldec.Spec
+	Constructor RuleConstructor
+	// Reversible rule types are the only ones configured by the decrypt
+	// command.
+	Reversible bool
+}
+
+var ruleTypes = map[string]*ruleType{}
+
+// RegisterRule adds a rule type that can be used in `rule "<name>"` blocks.
+// It is meant to be called from init functions and panics if a rule type with
+// the same name is already registered.
+func RegisterRule(name string, spec hcldec.Spec, constructor RuleConstructor) {
+	if _, ok := ruleTypes[name]; ok {
+		panic(fmt.Sprintf("rule type %s is already registered", name))
+	}
+	ruleTypes[name] = &ruleType{Spec: spec, Constructor: constructor}
+}
+
+// RegisterReversibleRule adds a rule type like RegisterRule whose rules
+// implement ReversibleRule, so that the decrypt command configures them.
+func RegisterReversibleRule(name string, spec hcldec.Spec, constructor RuleConstructor) {
+	RegisterRule(name, spec, constructor)
+	ruleTypes[name].Reversible = true
+}
+
+// RuleNames returns the names of all registered rule types in order.
+func RuleNames() []string {
+	names := make([]string, 0, len(ruleTypes))
+	for name := range ruleTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// A ReversibleRule can undo its changes to a row, which the decrypt command
+// uses to restore the original values of a dump.
+type ReversibleRule interface {
+	Rule
+	Reverse(*Row) error
+}
+
+// A BufferedRule needs to see every row of a table before it can change
+// them. Apply is called for each row as it is read and Flush once the whole
+// table has been read, before any of its rows are written.
+type BufferedRule interface {
+	Rule
+	Flush() error
+}
+
+// A ColumnChecker reports whether a rule can write valid values to a column.
+// Rules are checked against the schema of their table when the config is
+// read.
+type ColumnChecker interface {
+	CheckColumn(*Column) error
+}
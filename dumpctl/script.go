@@ -0,0 +1,197 @@
+package dumpctl
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/pingcap/tidb/types"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+	"go.starlark.net/starlark"
+)
+
+// ScriptRule calls a Starlark function for each row. The function receives
+// the values of the row as a dict and returns a dict of the values to change,
+// or None to leave the row as it is.
+type ScriptRule struct {
+	Script   *string `cty:"script"`
+	File     *string `cty:"file"`
+	Function string  `cty:"function"`
+	MaxSteps uint64  `cty:"max_steps"`
+	Callable starlark.Callable
+	Thread   *starlark.Thread
+}
+
+var scriptRuleDefaultSpec = hcldec.ObjectSpec{
+	"script": &hcldec.AttrSpec{
+		Name: "script",
+		Type: cty.String,
+	},
+	"file": &hcldec.AttrSpec{
+		Name: "file",
+		Type: cty.String,
+	},
+	"function": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "function",
+			Type: cty.String,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.StringVal("transform")},
+	},
+	"max_steps": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "max_steps",
+			Type: cty.Number,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.NumberIntVal(1000000)},
+	},
+}
+
+// starlarkValue converts a datum to a Starlark value. Integers and floats
+// become numbers, NULL becomes None and all other values, including
+// decimals, become strings.
+func starlarkValue(datum types.Datum) (starlark.Value, error) {
+	switch datum.Kind() {
+	case types.KindNull:
+		return starlark.None, nil
+	case types.KindInt64:
+		return starlark.MakeInt64(datum.GetInt64()), nil
+	case types.KindUint64:
+		return starlark.MakeUint64(datum.GetUint64()), nil
+	case types.KindFloat32, types.KindFloat64:
+		return starlark.Float(datum.GetFloat64()), nil
+	}
+	s, err := datum.ToString()
+	return starlark.String(s), err
+}
+
+// columnStarlarkValue converts a value returned by a script to a value for a
+// column.
+func columnStarlarkValue(value starlark.Value) (interface{}, error) {
+	switch v := value.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case starlark.String:
+		return string(v), nil
+	case starlark.Bool:
+		if v {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case starlark.Int:
+		if i, ok := v.Int64(); ok {
+			return i, nil
+		}
+		if u, ok := v.Uint64(); ok {
+			return u, nil
+		}
+		return nil, fmt.Errorf("%s is out of range", v)
+	case starlark.Float:
+		return float64(v), nil
+	default:
+		return nil, fmt.Errorf("cannot write a value of type %s", value.Type())
+	}
+}
+
+func (r *ScriptRule) Apply(row *Row) error {
+	values := starlark.NewDict(len(row.Table.Columns))
+	for name, column := range row.Table.Columns {
+		datum, ok := row.Datum(column)
+		if !ok {
+			continue
+		}
+		value, err := starlarkValue(datum)
+		if err != nil {
+			return err
+		}
+		values.SetKey(starlark.String(name), value)
+	}
+
+	// every row gets the full budget of steps
+	r.Thread.Steps = 0
+	result, err := starlark.Call(r.Thread, r.Callable, starlark.Tuple{values}, nil)
+	if err != nil {
+		if r.Thread.Steps >= r.MaxSteps {
+			return fmt.Errorf("%s took more than max_steps (%d) for row %d of %s: %w", r.Function, r.MaxSteps, row.Number, row.Table, err)
+		}
+		return err
+	}
+	if result == starlark.None {
+		return nil
+	}
+	changes, ok := result.(*starlark.Dict)
+	if !ok {
+		return fmt.Errorf("%s must return a dict or None, not %s", r.Function, result.Type())
+	}
+	for _, item := range changes.Items() {
+		name, ok := starlark.AsString(item[0])
+		if !ok {
+			return fmt.Errorf("%s returned a key that is not a column name: %s", r.Function, item[0])
+		}
+		column, ok := row.Table.Columns[name]
+		if !ok {
+			return fmt.Errorf("%s returned column %s, which %s does not have", r.Function, name, row.Table)
+		}
+		value, err := columnStarlarkValue(item[1])
+		if err != nil {
+			return fmt.Errorf("%s returned an invalid value for column %s: %w", r.Function, name, err)
+		}
+		row.SetValue(column, value)
+	}
+	return nil
+}
+
+func init() {
+	RegisterRule("script", scriptRuleDefaultSpec, func(value cty.Value, block *hcl.Block, table *Table) (Rule, error) {
+		return NewScriptRule(value, table.Database.Config)
+	})
+}
+
+func NewScriptRule(value cty.Value, config *Config) (*ScriptRule, error) {
+	rule := &ScriptRule{}
+	err := gocty.FromCtyValue(value, &rule)
+	if err == nil && rule.MaxSteps < 1 {
+		err = fmt.Errorf("max_steps must be at least 1")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var filename string
+	var src interface{}
+	switch {
+	case rule.Script != nil && rule.File == nil:
+		filename, src = "script", *rule.Script
+	case rule.File != nil && rule.Script == nil:
+		filename = config.Path(*rule.File)
+		if src, err = os.ReadFile(filename); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("one of script or file is required")
+	}
+
+	rule.Thread = &starlark.Thread{
+		Name: filename,
+		Print: func(thread *starlark.Thread, msg string) {
+			log.Printf("%s: %s", thread.Name, msg)
+		},
+	}
+	rule.Thread.SetMaxExecutionSteps(rule.MaxSteps)
+	globals, err := starlark.ExecFile(rule.Thread, filename, src, nil)
+	if err != nil {
+		if rule.Thread.Steps >= rule.MaxSteps {
+			return nil, fmt.Errorf("%s took more than max_steps (%d) to load: %w", filename, rule.MaxSteps, err)
+		}
+		return nil, err
+	}
+	callable, ok := globals[rule.Function].(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf("%s does not define a function named %s", filename, rule.Function)
+	}
+	rule.Callable = callable
+	return rule, nil
+}
@@ -0,0 +1,107 @@
+package dumpctl
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/pingcap/tidb/types"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// SetRule assigns columns from expressions that may reference the other
+// columns of the row.
+type SetRule struct {
+	Attributes hcl.Attributes
+}
+
+func init() {
+	RegisterRule("set", nil, func(value cty.Value, block *hcl.Block, table *Table) (Rule, error) {
+		rule, diags := NewSetRule(block, table)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		return rule, nil
+	})
+}
+
+func NewSetRule(block *hcl.Block, table *Table) (*SetRule, hcl.Diagnostics) {
+	content, diags := block.Body.Content(table.CustomBodySchema())
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	if len(content.Attributes) == 0 {
+		attrRange := block.Body.MissingItemRange()
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "set rule must assign at least one column",
+			Subject:  &attrRange,
+		})
+	}
+	for _, attr := range content.Attributes {
+		diags = append(diags, checkRowReferences(attr.Expr, table)...)
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return &SetRule{Attributes: content.Attributes}, diags
+}
+
+// sqlValue converts the result of an expression to a literal value.
+func sqlValue(value cty.Value) (interface{}, error) {
+	if value.IsNull() {
+		return nil, nil
+	}
+	if !value.IsWhollyKnown() {
+		return nil, fmt.Errorf("value is not known")
+	}
+	switch value.Type() {
+	case cty.String:
+		return value.AsString(), nil
+	case cty.Bool:
+		if value.True() {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case cty.Number:
+		bf := value.AsBigFloat()
+		if bf.IsInt() {
+			if i, accuracy := bf.Int64(); accuracy == big.Exact {
+				return i, nil
+			}
+			if u, accuracy := bf.Uint64(); accuracy == big.Exact {
+				return u, nil
+			}
+		}
+		dec := new(types.MyDecimal)
+		err := dec.FromString([]byte(bf.Text('f', -1)))
+		return dec, err
+	default:
+		return nil, fmt.Errorf("cannot write a value of type %s", value.Type().FriendlyName())
+	}
+}
+
+func (r *SetRule) Apply(row *Row) error {
+	// every expression sees the row as it was before this rule
+	ctx := row.EvalContext()
+	values := make(map[*Column]interface{}, len(r.Attributes))
+	for name, attr := range r.Attributes {
+		column, ok := row.Table.Columns[name]
+		if !ok {
+			continue
+		}
+		result, diags := attr.Expr.Value(ctx)
+		if diags.HasErrors() {
+			return diags
+		}
+		value, err := sqlValue(result)
+		if err != nil {
+			return fmt.Errorf("cannot set column %s: %w", name, err)
+		}
+		values[column] = value
+	}
+	for column, value := range values {
+		row.SetValue(column, value)
+	}
+	return nil
+}
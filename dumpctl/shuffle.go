@@ -0,0 +1,107 @@
+package dumpctl
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/pingcap/tidb/types"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+// ShuffleRule permutes the values of its columns among the rows dumped for a
+// table, optionally only among rows with the same value in `group_by`. The
+// columns of one rule are moved together, so values that belong together
+// (e.g. a city and its postcode) stay together.
+type ShuffleRule struct {
+	Columns []string `cty:"columns"`
+	GroupBy *string  `cty:"group_by"`
+	Groups  map[string][]*Row
+}
+
+var shuffleRuleDefaultSpec = hcldec.ObjectSpec{
+	"columns": ColumnSpec,
+	"group_by": &hcldec.AttrSpec{
+		Name: "group_by",
+		Type: cty.String,
+	},
+}
+
+func (r *ShuffleRule) groupKey(row *Row) (string, error) {
+	if r.GroupBy == nil {
+		return "", nil
+	}
+	column, ok := row.Table.Columns[*r.GroupBy]
+	if !ok {
+		return "", fmt.Errorf("%s has no column named %s", row.Table, *r.GroupBy)
+	}
+	datum, ok := row.Datum(column)
+	if !ok || datum.Kind() == types.KindNull {
+		return "NULL", nil
+	}
+	s, err := datum.ToString()
+	return "'" + s, err
+}
+
+// Apply only records the row. Its values are shuffled by Flush once all rows
+// of the table have been read.
+func (r *ShuffleRule) Apply(row *Row) error {
+	key, err := r.groupKey(row)
+	if err != nil {
+		return err
+	}
+	if r.Groups == nil {
+		r.Groups = make(map[string][]*Row)
+	}
+	r.Groups[key] = append(r.Groups[key], row)
+	return nil
+}
+
+func (r *ShuffleRule) Flush() error {
+	for _, rows := range r.Groups {
+		if len(rows) < 2 {
+			continue
+		}
+		table := rows[0].Table
+		var positions []int64
+		for _, columnName := range r.Columns {
+			if column, ok := table.Columns[columnName]; ok {
+				positions = append(positions, column.Position-1)
+			}
+		}
+		rand.Shuffle(len(rows), func(i, j int) {
+			for _, position := range positions {
+				a, b := *rows[i].Values, *rows[j].Values
+				a[position], b[position] = b[position], a[position]
+			}
+		})
+	}
+	r.Groups = nil
+	return nil
+}
+
+func init() {
+	RegisterRule("shuffle", shuffleRuleDefaultSpec, func(value cty.Value, block *hcl.Block, table *Table) (Rule, error) {
+		rule, err := NewShuffleRule(value)
+		if err != nil {
+			return nil, err
+		}
+		if rule.GroupBy != nil {
+			if diags := table.checkColumnAttribute(block.Body, "group_by", *rule.GroupBy); diags.HasErrors() {
+				return nil, diags
+			}
+		}
+		return rule, nil
+	})
+}
+
+func NewShuffleRule(value cty.Value) (*ShuffleRule, error) {
+	rule := &ShuffleRule{}
+	err := gocty.FromCtyValue(value, &rule)
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
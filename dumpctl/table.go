@@ -0,0 +1,555 @@
+package dumpctl
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	_ "github.com/doug-martin/goqu/v9/dialect/mysql"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/heimdalr/dag"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+var dialect = goqu.Dialect("mysql")
+
+type Where struct {
+	Reference *Column
+	Value     cty.Value
+}
+
+type Table struct {
+	Name        string  `hcl:"name,label"`
+	SimpleWhere string  `hcl:"where,optional"`
+	Limit       int     `hcl:"limit,optional"`
+	Order       string  `hcl:"order,optional"`
+	SampleRate  float64 `hcl:"sample_rate,optional"`
+	// PoliciesAttr names the policies whose rules apply to the table.
+	PoliciesAttr *hcl.Attribute `hcl:"policies,optional"`
+	// QuasiIdentifiersAttr and K configure KAnonymity.
+	QuasiIdentifiersAttr *hcl.Attribute `hcl:"quasi_identifiers,optional"`
+	K                    int            `hcl:"k,optional"`
+	KAnonymity           *KAnonymity
+	Rules                []Rule
+	// BufferedRules are the rules that require all rows of the table to be
+	// read before any are written.
+	BufferedRules []BufferedRule
+	// Closers are the rules that hold resources, such as processes, that are
+	// released when the dump is finished.
+	Closers     []io.Closer
+	Columns     map[string]*Column
+	Body        hcl.Body `hcl:",remain"`
+	BodyContent *hcl.BodyContent
+	Database    *Database
+	Wheres      []map[string]*Where
+	OutFile     *os.File
+	Dumped      bool
+}
+
+var tableSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{
+			Type: "where",
+		},
+		{
+			Type:       "rule",
+			LabelNames: []string{"name"},
+		},
+		{
+			Type: "generalize",
+		},
+	},
+}
+
+func NewTable(db *Database, name string, block *hcl.Block) (table *Table, diags hcl.Diagnostics) {
+	table = &Table{
+		Name:     name,
+		Database: db,
+		Columns:  make(map[string]*Column),
+	}
+
+	diags = gohcl.DecodeBody(block.Body, table.EvalContext(true), table)
+	tableContent, moreDiags := table.Body.Content(tableSchema)
+	diags = append(diags, moreDiags...)
+	table.BodyContent = tableContent
+
+	return
+}
+
+func (t *Table) ReadDynamicConfig() (diags hcl.Diagnostics) {
+	diags = t.ReadRules()
+	kAnonymity, moreDiags := NewKAnonymity(t)
+	t.KAnonymity = kAnonymity
+	diags = append(diags, moreDiags...)
+	return append(diags, t.TrackDependencies(t.BodyContent.Blocks.OfType("where"))...)
+}
+
+// ReadRules adds the rules of the column patterns of the database, of the
+// table's policies and of the table itself, in this order.
+func (t *Table) ReadRules() (diags hcl.Diagnostics) {
+	for _, pattern := range t.Database.ColumnPatterns {
+		moreDiags := t.AddColumnPatternRules(pattern)
+		diags = append(diags, moreDiags...)
+	}
+	policies, moreDiags := t.ReadPolicies()
+	diags = append(diags, moreDiags...)
+	for _, policy := range policies {
+		moreDiags := t.AddPolicyRules(policy)
+		diags = append(diags, moreDiags...)
+	}
+	for _, ruleBlock := range t.BodyContent.Blocks.OfType("rule") {
+		_, moreDiags := t.AddRule(ruleBlock.Labels[0], ruleBlock)
+		if diags = append(diags, moreDiags...); moreDiags.HasErrors() {
+			continue
+		}
+	}
+	return
+}
+
+// ruleSchema holds the attributes every rule block accepts in addition to
+// those of its rule type.
+var ruleSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "paths"},
+		{Name: "when"},
+	},
+}
+
+func (t *Table) AddRule(ruleType string, ruleBlock *hcl.Block) (rule Rule, diags hcl.Diagnostics) {
+	return t.addRule(ruleType, ruleBlock, false)
+}
+
+// addRule adds a rule from a rule block. Rules of policies may name columns
+// the table does not have, which are left out. The rule is nil if none of its
+// columns are left.
+func (t *Table) addRule(ruleType string, ruleBlock *hcl.Block, fromPolicy bool) (rule Rule, diags hcl.Diagnostics) {
+	ruleContent, remain, diags := ruleBlock.Body.PartialContent(ruleSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	block := *ruleBlock
+	block.Body = remain
+
+	registered, ok := ruleTypes[ruleType]
+	if !ok {
+		attrRange := block.DefRange
+		return nil, hcl.Diagnostics{
+			&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("%s is not a recognized rule type", ruleType),
+				Detail:   fmt.Sprintf("The registered rule types are %s.", strings.Join(RuleNames(), ", ")),
+				Subject:  &attrRange,
+			},
+		}
+	}
+	// decrypting only needs the rules that can be reversed, which also spares
+	// it the keys of all other rules
+	if t.Database.Config.Decrypting && !registered.Reversible {
+		return nil, diags
+	}
+
+	ctx := t.EvalContext(false)
+	if fromPolicy {
+		ctx = t.policyEvalContext(block.Body)
+	}
+	value := cty.NullVal(cty.DynamicPseudoType)
+	if registered.Spec != nil {
+		value, diags = hcldec.Decode(block.Body, registered.Spec, ctx)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+	}
+	if fromPolicy {
+		if value, ok = t.policyColumns(value); !ok {
+			return nil, diags
+		}
+	}
+	rule, err := registered.Constructor(value, &block, t)
+	if moreDiags, ok := err.(hcl.Diagnostics); ok {
+		return nil, append(diags, moreDiags...)
+	}
+	if err != nil {
+		attrRange := block.Body.MissingItemRange()
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("error while configuring %s rule: %v", ruleType, err.Error()),
+			Subject:  &attrRange,
+		})
+	}
+
+	_, hasPaths := ruleContent.Attributes["paths"]
+	moreDiags := t.checkColumns(ruleType, rule, block.Body, ctx, !hasPaths, fromPolicy)
+	if diags = append(diags, moreDiags...); moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	buffered, isBuffered := rule.(BufferedRule)
+	closer, isCloser := rule.(io.Closer)
+
+	if paths, ok := ruleContent.Attributes["paths"]; ok {
+		if isBuffered {
+			return nil, diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("%s rules do not support paths", ruleType),
+				Subject:  paths.Range.Ptr(),
+			})
+		}
+		var moreDiags hcl.Diagnostics
+		rule, moreDiags = NewJSONPathRule(rule, block.Body, paths, ctx)
+		if diags = append(diags, moreDiags...); moreDiags.HasErrors() {
+			return nil, diags
+		}
+	}
+
+	if when, ok := ruleContent.Attributes["when"]; ok {
+		if t.Database.Config.Decrypting {
+			return nil, diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("cannot decrypt columns %s of %s, their %s rule has a when expression", strings.Join(ruleColumns(value), ", "), t, ruleType),
+				Detail:   "The rows the rule was applied to cannot be told apart in the dump, because the expression would see the values the rules have written.",
+				Subject:  when.Range.Ptr(),
+			})
+		}
+		var moreDiags hcl.Diagnostics
+		rule, moreDiags = NewConditionalRule(rule, when, t)
+		if diags = append(diags, moreDiags...); moreDiags.HasErrors() {
+			return nil, diags
+		}
+	}
+
+	t.Rules = append(t.Rules, rule)
+	if isBuffered {
+		t.BufferedRules = append(t.BufferedRules, buffered)
+	}
+	if isCloser {
+		t.Closers = append(t.Closers, closer)
+	}
+
+	return
+}
+
+// ruleColumns returns the columns in the decoded attributes of a rule.
+func ruleColumns(value cty.Value) (columns []string) {
+	if value.IsNull() || !value.Type().IsObjectType() || !value.Type().HasAttribute("columns") {
+		return
+	}
+	gocty.FromCtyValue(value.GetAttr("columns"), &columns)
+	return
+}
+
+// checkColumns reports the columns of a rule that the table does not have or
+// that the rule cannot write valid values to. Diagnostics point at the column
+// in the rule's `columns` attribute. Columns of policy rules that the table
+// does not have are not reported, policyColumns has left them out.
+func (t *Table) checkColumns(ruleType string, rule Rule, body hcl.Body, ctx *hcl.EvalContext, checkTypes, fromPolicy bool) (diags hcl.Diagnostics) {
+	content, _, _ := body.PartialContent(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "columns"}},
+	})
+	attr, ok := content.Attributes["columns"]
+	if !ok {
+		return
+	}
+	exprs, listDiags := hcl.ExprList(attr.Expr)
+	if listDiags.HasErrors() {
+		exprs = []hcl.Expression{attr.Expr}
+	}
+	checker, isChecker := rule.(ColumnChecker)
+
+	for _, expr := range exprs {
+		value, moreDiags := expr.Value(ctx)
+		if moreDiags.HasErrors() || !value.IsWhollyKnown() || value.IsNull() {
+			continue
+		}
+		var names []cty.Value
+		if value.Type() == cty.String {
+			names = []cty.Value{value}
+		} else if value.CanIterateElements() {
+			names = value.AsValueSlice()
+		}
+		for _, name := range names {
+			if name.IsNull() || name.Type() != cty.String {
+				continue
+			}
+			column, ok := t.Columns[name.AsString()]
+			if !ok && fromPolicy {
+				continue
+			}
+			if !ok {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  fmt.Sprintf("%s has no column named %s", t, name.AsString()),
+					Subject:  expr.Range().Ptr(),
+				})
+				continue
+			}
+			if !isChecker || !checkTypes {
+				continue
+			}
+			if err := checker.CheckColumn(column); err != nil {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  fmt.Sprintf("%s rule cannot be applied to %s column %s: %v", ruleType, column.Type, column.Name, err),
+					Subject:  expr.Range().Ptr(),
+				})
+			}
+		}
+	}
+	return
+}
+
+// checkColumnAttribute reports an attribute of a rule block, such as
+// `key_column`, that names a column the table does not have.
+func (t *Table) checkColumnAttribute(body hcl.Body, name, column string) (diags hcl.Diagnostics) {
+	if _, ok := t.Columns[column]; ok {
+		return
+	}
+	subject := body.MissingItemRange()
+	content, _, _ := body.PartialContent(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: name}},
+	})
+	if attr, ok := content.Attributes[name]; ok {
+		subject = attr.Expr.Range()
+	}
+	return diags.Append(&hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  fmt.Sprintf("%s has no column named %s", t, column),
+		Subject:  &subject,
+	})
+}
+
+func (t *Table) ReadSchema() (diags hcl.Diagnostics) {
+	log.Printf("DEBUG: reading schema for %s.%s\n", t.Database.Name, t.Name)
+	rows, err := t.Database.Config.Conn.Query(`
+SELECT COLUMN_NAME, DATA_TYPE, COLUMN_TYPE, ORDINAL_POSITION, CHARACTER_MAXIMUM_LENGTH,
+  IS_NULLABLE = 'YES', COLUMN_DEFAULT, NUMERIC_PRECISION, NUMERIC_SCALE, COLUMN_KEY
+from INFORMATION_SCHEMA.COLUMNS
+where TABLE_SCHEMA = ? and TABLE_NAME = ?
+order by ORDINAL_POSITION asc`, t.Database.Name, t.Name)
+	if err != nil {
+		diags = diags.Append(&hcl.Diagnostic{Summary: err.Error(), Severity: hcl.DiagError})
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var column Column
+		if err := rows.Scan(&column.Name, &column.Type, &column.ColumnType, &column.Position, &column.MaxLength, &column.Nullable, &column.Default, &column.Precision, &column.Scale, &column.Key); err != nil {
+			diags = diags.Append(&hcl.Diagnostic{Summary: err.Error(), Severity: hcl.DiagError})
+			continue
+		}
+		column.Table = t
+		t.Columns[column.Name] = &column
+	}
+
+	if err = rows.Err(); err != nil {
+		diags = diags.Append(&hcl.Diagnostic{Summary: err.Error(), Severity: hcl.DiagError})
+	}
+	if len(t.Columns) == 0 {
+		diags = diags.Append(&hcl.Diagnostic{Summary: fmt.Sprintf("Could not read schema of %s. Is it misspelled?", t.Name), Severity: hcl.DiagError})
+	}
+	return
+}
+
+func (t *Table) CustomBodySchema() (schema *hcl.BodySchema) {
+	schema = &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{},
+	}
+	for colName := range t.Columns {
+		schema.Attributes = append(schema.Attributes, hcl.AttributeSchema{
+			Name:     colName,
+			Required: false,
+		})
+	}
+	return
+}
+
+func (t *Table) TrackDependencies(wheres []*hcl.Block) (diags hcl.Diagnostics) {
+	log.Printf("DEBUG: building dependency graph for %+v\n", t.Name)
+
+	for _, block := range wheres {
+		whereContent, moreDiags := block.Body.Content(t.CustomBodySchema())
+		diags = append(diags, moreDiags...)
+		whereGroup := make(map[string]*Where)
+		for colName, attr := range whereContent.Attributes {
+			variables := attr.Expr.Variables()
+			if len(variables) > 1 {
+				diags = diags.Append(&hcl.Diagnostic{
+					Summary:  "Cannot make more than one table reference in where condition",
+					Severity: hcl.DiagError,
+					Subject:  attr.Expr.Range().Ptr(),
+				})
+				continue
+			}
+			if len(variables) == 0 {
+				value, moreDiags := attr.Expr.Value(nil)
+				diags = append(diags, moreDiags...)
+				if moreDiags.HasErrors() {
+					continue
+				}
+				whereGroup[colName] = &Where{Value: value}
+			} else {
+				reference, moreDiags := variables[0].TraverseAbs(t.Database.EvalContext())
+				diags = append(diags, moreDiags...)
+				if moreDiags.HasErrors() {
+					continue
+				}
+				parts := strings.Split(reference.AsString(), ".")
+				tableRefName, columnRefName := parts[0], parts[1]
+
+				if err := t.Database.DAG.AddEdge(tableRefName, t.Name); err != nil {
+					var severity hcl.DiagnosticSeverity
+					switch err.(type) {
+					case dag.EdgeLoopError:
+						severity = hcl.DiagError
+						err = fmt.Errorf("table `%s` dependency on `%s` would create a circular dependency", t.Name, tableRefName)
+					case dag.EdgeDuplicateError:
+						continue
+					default:
+						severity = hcl.DiagError
+					}
+					diags = diags.Append(&hcl.Diagnostic{
+						Summary:  err.Error(),
+						Severity: severity,
+						Subject:  attr.Expr.Range().Ptr(),
+					})
+				}
+				whereGroup[colName] = &Where{
+					Reference: t.Database.Tables[tableRefName].Columns[columnRefName],
+				}
+			}
+		}
+		t.Wheres = append(t.Wheres, whereGroup)
+	}
+	return
+}
+
+func (t *Table) Select(selectCol string) string {
+	expressions := []goqu.Expression{}
+	if len(t.SimpleWhere) != 0 {
+		expressions = append(expressions, goqu.L(t.SimpleWhere))
+	}
+	if t.SampleRate > 0 {
+		expressions = append(expressions, goqu.L(fmt.Sprintf("(crc32(id) %% %d = 0)", int(1/t.SampleRate))))
+	}
+
+	orExpressions := []goqu.Expression{}
+	for _, whereGroup := range t.Wheres {
+		conditions := make(goqu.Ex)
+
+		for colName, where := range whereGroup {
+			if where.Reference != nil {
+				conditions[colName] = goqu.Op{
+					"in": goqu.L(fmt.Sprintf("(select * from (%s) _tmp_%s)", where.Reference.Table.Select(where.Reference.Name), where.Reference.Table.Name)),
+				}
+			} else if where.Value.Type() == cty.String {
+				var s string
+				err := gocty.FromCtyValue(where.Value, &s)
+				if err != nil {
+					log.Fatalf(err.Error())
+				}
+				conditions[colName] = s
+			} else if where.Value.Type() == cty.Number {
+				var i int64
+				err := gocty.FromCtyValue(where.Value, &i)
+				if err != nil {
+					log.Fatalf(err.Error())
+				}
+				conditions[colName] = i
+			} else if where.Value.Type().IsTupleType() {
+				log.Fatalf("don't know how to decode tuple yet")
+			} else if where.Value.Type() == cty.NilType {
+				conditions[colName] = nil
+			}
+		}
+		orExpressions = append(orExpressions, conditions)
+	}
+
+	sql, _, _ := dialect.From(t.Name).Select(selectCol).Where(
+		append(expressions, goqu.Or(orExpressions...))...,
+	).ToSQL()
+
+	if len(t.Order) != 0 {
+		sql = fmt.Sprintf("%s order by %s", sql, t.Order)
+	}
+	if t.Limit > 0 {
+		sql = fmt.Sprintf("%s limit %d", sql, t.Limit)
+	}
+
+	return sql
+}
+
+func (t *Table) Where() string {
+	fullSelect := t.Select("*")
+	emptySelect, _, _ := dialect.From(t.Name).ToSQL()
+
+	return strings.Replace(
+		strings.Replace(
+			fullSelect,
+			fmt.Sprintf("%s", emptySelect),
+			"", 1,
+		),
+		" WHERE ",
+		"", 1,
+	)
+}
+
+func (t *Table) ContextVariables(withTablePrefix bool) map[string]cty.Value {
+	vars := make(map[string]cty.Value)
+	for _, column := range t.Columns {
+		if withTablePrefix {
+			vars[column.Name] = cty.StringVal(fmt.Sprintf("%s.%s", column.Table.Name, column.Name))
+		} else {
+			vars[column.Name] = cty.StringVal(column.Name)
+		}
+	}
+	return vars
+}
+
+func (t *Table) EvalContext(withTablePrefix bool) *hcl.EvalContext {
+	now := function.New(&function.Spec{
+		Params: []function.Parameter{},
+		Type:   function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			ts := t.Database.Config.Started.Format(time.RFC3339)
+			return cty.StringVal(ts), nil
+		}})
+	return &hcl.EvalContext{
+		Variables: t.ContextVariables(withTablePrefix),
+		Functions: map[string]function.Function{
+			"timeadd":      stdlib.TimeAddFunc,
+			"now":          now,
+			"formatdate":   stdlib.FormatDateFunc,
+			"format":       stdlib.FormatFunc,
+			"upper":        stdlib.UpperFunc,
+			"lower":        stdlib.LowerFunc,
+			"title":        stdlib.TitleFunc,
+			"substr":       stdlib.SubstrFunc,
+			"strlen":       stdlib.StrlenFunc,
+			"trimspace":    stdlib.TrimSpaceFunc,
+			"replace":      stdlib.ReplaceFunc,
+			"regex":        stdlib.RegexFunc,
+			"regexreplace": stdlib.RegexReplaceFunc,
+			"join":         stdlib.JoinFunc,
+			"split":        stdlib.SplitFunc,
+			"coalesce":     stdlib.CoalesceFunc,
+			"abs":          stdlib.AbsoluteFunc,
+			"ceil":         stdlib.CeilFunc,
+			"floor":        stdlib.FloorFunc,
+			"min":          stdlib.MinFunc,
+			"max":          stdlib.MaxFunc,
+		},
+	}
+}
+
+func (t *Table) String() string {
+	return fmt.Sprintf("`%s`", t.Name)
+}
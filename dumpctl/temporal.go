@@ -0,0 +1,168 @@
+package dumpctl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// temporal is a DATE, DATETIME, TIMESTAMP or TIME value as it is written by
+// mysqldump. Unlike time.Time it can hold zero dates and TIME values outside
+// of a single day, both of which MySQL allows.
+type temporal struct {
+	Year, Month, Day     int
+	Hour, Minute, Second int
+	Fraction             string
+	Negative             bool
+	HasDate, HasTime     bool
+}
+
+func parseTemporal(s string) (*temporal, error) {
+	t := &temporal{}
+	datePart, timePart := s, ""
+	if i := strings.IndexByte(s, ' '); i >= 0 {
+		datePart, timePart = s[:i], s[i+1:]
+	} else if strings.Contains(s, ":") {
+		datePart, timePart = "", s
+	}
+
+	if len(datePart) > 0 {
+		parts := strings.Split(datePart, "-")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("cannot parse %q as a date", s)
+		}
+		var err error
+		for i, dst := range []*int{&t.Year, &t.Month, &t.Day} {
+			if *dst, err = strconv.Atoi(parts[i]); err != nil {
+				return nil, fmt.Errorf("cannot parse %q as a date", s)
+			}
+		}
+		t.HasDate = true
+	}
+
+	if len(timePart) > 0 {
+		if timePart[0] == '-' {
+			t.Negative = true
+			timePart = timePart[1:]
+		}
+		if i := strings.IndexByte(timePart, '.'); i >= 0 {
+			timePart, t.Fraction = timePart[:i], timePart[i+1:]
+		}
+		parts := strings.Split(timePart, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("cannot parse %q as a time", s)
+		}
+		var err error
+		for i, dst := range []*int{&t.Hour, &t.Minute, &t.Second} {
+			if *dst, err = strconv.Atoi(parts[i]); err != nil {
+				return nil, fmt.Errorf("cannot parse %q as a time", s)
+			}
+		}
+		t.HasTime = true
+	}
+
+	return t, nil
+}
+
+// IsZero reports whether the value has a zero date such as 0000-00-00.
+func (t *temporal) IsZero() bool {
+	return t.HasDate && (t.Year == 0 || t.Month == 0 || t.Day == 0)
+}
+
+var (
+	minTimestamp = time.Date(1970, 1, 1, 0, 0, 1, 0, time.UTC)
+	maxTimestamp = time.Date(2038, 1, 19, 3, 14, 7, 0, time.UTC)
+)
+
+// Validate reports values that a column of columnType rejects in strict
+// mode, such as zero dates, impossible dates and timestamps out of range.
+// Timestamps are checked in UTC, which dumps are written in.
+func (t *temporal) Validate(columnType string) error {
+	if t.HasTime && (t.Minute > 59 || t.Second > 59) {
+		return fmt.Errorf("%s is not a valid time of day", t)
+	}
+	if columnType == "time" {
+		if !t.HasTime && !t.HasDate || t.Hour > 838 {
+			return fmt.Errorf("%s is out of range for time", t)
+		}
+		return nil
+	}
+	if !t.HasDate {
+		return fmt.Errorf("%s has no date", t)
+	}
+	if t.IsZero() {
+		return fmt.Errorf("%s is a zero date", t)
+	}
+	if t.HasTime && t.Hour > 23 {
+		return fmt.Errorf("%s is not a valid time of day", t)
+	}
+	tm := t.Time()
+	if tm.Year() != t.Year || int(tm.Month()) != t.Month || tm.Day() != t.Day {
+		return fmt.Errorf("%s is not a valid date", t)
+	}
+	if columnType == "timestamp" {
+		if tm.Before(minTimestamp) || tm.Truncate(time.Second).After(maxTimestamp) {
+			return fmt.Errorf("%s is out of range for timestamp", t)
+		}
+	} else if t.Year > 9999 {
+		return fmt.Errorf("%s is out of range for %s", t, columnType)
+	}
+	return nil
+}
+
+// Time returns the value as a time.Time in UTC.
+func (t *temporal) Time() time.Time {
+	nsec := 0
+	if len(t.Fraction) > 0 {
+		fraction := (t.Fraction + "000000000")[:9]
+		nsec, _ = strconv.Atoi(fraction)
+	}
+	return time.Date(t.Year, time.Month(t.Month), t.Day, t.Hour, t.Minute, t.Second, nsec, time.UTC)
+}
+
+// SetTime replaces the date and time of day while keeping the fractional
+// second precision of the original value.
+func (t *temporal) SetTime(tm time.Time) {
+	t.Year, t.Month, t.Day = tm.Year(), int(tm.Month()), tm.Day()
+	t.Hour, t.Minute, t.Second = tm.Hour(), tm.Minute(), tm.Second()
+	if len(t.Fraction) > 0 {
+		t.Fraction = fmt.Sprintf("%09d", tm.Nanosecond())[:len(t.Fraction)]
+	}
+}
+
+// Truncate zeroes every component of the value that is finer than precision,
+// which is one of day, month or year.
+func (t *temporal) Truncate(precision string) {
+	switch precision {
+	case "year":
+		t.Month = 1
+		fallthrough
+	case "month":
+		t.Day = 1
+		fallthrough
+	case "day":
+		t.Hour, t.Minute, t.Second = 0, 0, 0
+		t.Fraction = strings.Repeat("0", len(t.Fraction))
+	}
+}
+
+func (t *temporal) String() string {
+	var sb strings.Builder
+	if t.HasDate {
+		fmt.Fprintf(&sb, "%04d-%02d-%02d", t.Year, t.Month, t.Day)
+	}
+	if t.HasTime {
+		if t.HasDate {
+			sb.WriteByte(' ')
+		}
+		if t.Negative {
+			sb.WriteByte('-')
+		}
+		fmt.Fprintf(&sb, "%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+		if len(t.Fraction) > 0 {
+			fmt.Fprintf(&sb, ".%s", t.Fraction)
+		}
+	}
+	return sb.String()
+}
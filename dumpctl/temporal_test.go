@@ -0,0 +1,105 @@
+package dumpctl
+
+import "testing"
+
+func TestParseTemporal(t *testing.T) {
+	tests := []struct {
+		s                string
+		hasDate, hasTime bool
+		zero             bool
+	}{
+		{"2021-03-04", true, false, false},
+		{"2021-03-04 05:06:07", true, true, false},
+		{"2021-03-04 05:06:07.120", true, true, false},
+		{"838:59:59", false, true, false},
+		{"-12:00:00.5", false, true, false},
+		{"0000-00-00", true, false, true},
+		{"2021-00-04 00:00:00", true, true, true},
+	}
+	for _, test := range tests {
+		value, err := parseTemporal(test.s)
+		if err != nil {
+			t.Errorf("parseTemporal(%q): %v", test.s, err)
+			continue
+		}
+		if value.HasDate != test.hasDate || value.HasTime != test.hasTime {
+			t.Errorf("parseTemporal(%q) has date %v and time %v, want %v and %v", test.s, value.HasDate, value.HasTime, test.hasDate, test.hasTime)
+		}
+		if value.IsZero() != test.zero {
+			t.Errorf("parseTemporal(%q).IsZero() = %v", test.s, value.IsZero())
+		}
+		if got := value.String(); got != test.s {
+			t.Errorf("parseTemporal(%q).String() = %q", test.s, got)
+		}
+	}
+
+	for _, s := range []string{"2021-03", "2021-03-x", "05:06", "2021-03-04 05:06", "2021-03-04 aa:06:07"} {
+		if _, err := parseTemporal(s); err == nil {
+			t.Errorf("parseTemporal(%q) did not fail", s)
+		}
+	}
+}
+
+func TestTemporalTruncate(t *testing.T) {
+	tests := []struct{ precision, want string }{
+		{"day", "2021-03-04 00:00:00.000"},
+		{"month", "2021-03-01 00:00:00.000"},
+		{"year", "2021-01-01 00:00:00.000"},
+	}
+	for _, test := range tests {
+		value, err := parseTemporal("2021-03-04 05:06:07.890")
+		if err != nil {
+			t.Fatal(err)
+		}
+		value.Truncate(test.precision)
+		if got := value.String(); got != test.want {
+			t.Errorf("truncated to %s: got %s, want %s", test.precision, got, test.want)
+		}
+	}
+}
+
+func TestTemporalValidate(t *testing.T) {
+	tests := []struct {
+		columnType, s string
+		valid         bool
+	}{
+		{"date", "2021-02-28", true},
+		{"date", "2021-02-29", false},
+		{"date", "2020-02-29", true},
+		{"date", "0000-00-00", false},
+		{"date", "2021-04-31", false},
+		{"datetime", "2021-03-04 23:59:59", true},
+		{"datetime", "2021-03-04 24:00:00", false},
+		{"datetime", "2021-03-04 12:60:00", false},
+		{"timestamp", "1970-01-01 00:00:00", false},
+		{"timestamp", "1970-01-01 00:00:01", true},
+		{"timestamp", "2038-01-19 03:14:07.999", true},
+		{"timestamp", "2038-01-19 03:14:08", false},
+		{"time", "838:59:59", true},
+		{"time", "-838:59:59", true},
+		{"time", "839:00:00", false},
+		{"time", "12:00:60", false},
+	}
+	for _, test := range tests {
+		value, err := parseTemporal(test.s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := value.Validate(test.columnType); (err == nil) != test.valid {
+			t.Errorf("Validate(%s) of %s returned %v", test.columnType, test.s, err)
+		}
+	}
+}
+
+// TestRedactedTemporalValues makes sure that redacted values can be imported.
+func TestRedactedTemporalValues(t *testing.T) {
+	for columnType, s := range redactedTemporalValues {
+		value, err := parseTemporal(s)
+		if err == nil {
+			err = value.Validate(columnType)
+		}
+		if err != nil {
+			t.Errorf("redacted %s %s is invalid: %v", columnType, s, err)
+		}
+	}
+}
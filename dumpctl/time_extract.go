@@ -0,0 +1,121 @@
+package dumpctl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/pingcap/tidb/types"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+type TimeExtractRule struct {
+	Columns []string `cty:"columns"`
+	Keep    string   `cty:"keep"`
+}
+
+var timeExtractRuleDefaultSpec = hcldec.ObjectSpec{
+	"columns": ColumnSpec,
+	"keep": &hcldec.AttrSpec{
+		Name:     "keep",
+		Type:     cty.String,
+		Required: true,
+	},
+}
+
+var timeExtractPrecisions = map[string]string{
+	"year":       "year",
+	"year_month": "month",
+	"date":       "day",
+}
+
+// Extract keeps only the configured components of a temporal value of a
+// column. Values that keep only their time of day are moved to the date of
+// redacted values of the column's type, since zero dates cannot be imported.
+func (r *TimeExtractRule) Extract(column *Column, s string) (string, error) {
+	value, err := parseTemporal(s)
+	if err != nil {
+		return "", err
+	}
+	if r.Keep == "time" {
+		if value.HasDate {
+			epoch, err := parseTemporal(redactedTemporalValues[column.Type])
+			if err != nil || !epoch.HasDate {
+				return "", fmt.Errorf("cannot keep the time of %s column %s", column.Type, column.Name)
+			}
+			value.Year, value.Month, value.Day = epoch.Year, epoch.Month, epoch.Day
+		}
+		return value.String(), nil
+	}
+	if !value.HasDate {
+		if r.Keep != "date" {
+			return "", fmt.Errorf("cannot keep %s of time value %s", r.Keep, s)
+		}
+		value.Hour, value.Minute, value.Second, value.Negative = 0, 0, 0, false
+		value.Fraction = strings.Repeat("0", len(value.Fraction))
+		return value.String(), nil
+	}
+	if !value.IsZero() {
+		value.Truncate(timeExtractPrecisions[r.Keep])
+	}
+	return value.String(), nil
+}
+
+func (r *TimeExtractRule) Apply(row *Row) error {
+	for _, columnName := range r.Columns {
+		column, ok := row.Table.Columns[columnName]
+		if !ok {
+			continue
+		}
+
+		datum, ok := row.Datum(column)
+		if !ok || datum.Kind() == types.KindNull || column.Type == "year" {
+			continue
+		}
+		switch datum.Kind() {
+		case types.KindString, types.KindBytes, types.KindMysqlTime, types.KindMysqlDuration:
+			s, err := datum.ToString()
+			if err != nil {
+				return err
+			}
+			value, err := r.Extract(column, s)
+			if err != nil {
+				return fmt.Errorf("cannot extract from column %s: %w", column.Name, err)
+			}
+			row.SetValue(column, value)
+		default:
+			return fmt.Errorf("cannot extract %s from column %s with kind %d", r.Keep, column.Name, datum.Kind())
+		}
+	}
+	return nil
+}
+
+func (r *TimeExtractRule) CheckColumn(column *Column) error {
+	if !column.IsTemporal() {
+		return fmt.Errorf("only temporal columns can be extracted from")
+	}
+	if column.Type == "time" && r.Keep != "date" && r.Keep != "time" {
+		return fmt.Errorf("time columns have no %s to keep", strings.ReplaceAll(r.Keep, "_", " and "))
+	}
+	return nil
+}
+
+func init() {
+	RegisterRule("time_extract", timeExtractRuleDefaultSpec, func(value cty.Value, block *hcl.Block, table *Table) (Rule, error) {
+		return NewTimeExtractRule(value)
+	})
+}
+
+func NewTimeExtractRule(value cty.Value) (*TimeExtractRule, error) {
+	rule := &TimeExtractRule{}
+	err := gocty.FromCtyValue(value, &rule)
+	if _, ok := timeExtractPrecisions[rule.Keep]; err == nil && !ok && rule.Keep != "time" {
+		err = fmt.Errorf("keep must be one of year, year_month, date or time")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
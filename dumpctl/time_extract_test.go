@@ -0,0 +1,24 @@
+package dumpctl
+
+import "testing"
+
+func TestTimeExtractCheckColumn(t *testing.T) {
+	tests := []struct {
+		keep, columnType string
+		ok               bool
+	}{
+		{"year", "datetime", true},
+		{"year", "time", false},
+		{"year_month", "time", false},
+		{"date", "time", true},
+		{"time", "time", true},
+		{"date", "varchar", false},
+	}
+	for _, test := range tests {
+		rule := &TimeExtractRule{Keep: test.keep}
+		column := &Column{Name: "at", Type: test.columnType, ColumnType: test.columnType}
+		if err := rule.CheckColumn(column); (err == nil) != test.ok {
+			t.Errorf("keeping %s of a %s column: %v", test.keep, test.columnType, err)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package dumpctl
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/pingcap/tidb/types"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+type TokenizeRule struct {
+	Columns []string `cty:"columns"`
+	KeyEnv  string   `cty:"key_env"`
+	KeyFile *string  `cty:"key_file"`
+	Key     []byte
+}
+
+var tokenizeRuleDefaultSpec = hcldec.ObjectSpec{
+	"columns": ColumnSpec,
+	"key_env": &hcldec.DefaultSpec{
+		Primary: &hcldec.AttrSpec{
+			Name: "key_env",
+			Type: cty.String,
+		},
+		Default: &hcldec.LiteralSpec{Value: cty.StringVal("DUMPCTL_TOKEN_KEY")},
+	},
+	"key_file": &hcldec.AttrSpec{
+		Name: "key_file",
+		Type: cty.String,
+	},
+}
+
+// token derives a keyed digest of value. The same key and value always
+// produce the same token.
+func token(key []byte, value string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+// tokenValue formats a token so that it fits in the column it replaces.
+func tokenValue(column *Column, sum []byte) (interface{}, error) {
+	switch {
+	case column.IsInteger():
+		_, max := column.IntegerRange()
+		n := binary.BigEndian.Uint64(sum[:8])
+		if max == math.MaxUint64 {
+			return n, nil
+		}
+		return n % (max + 1), nil
+	case column.IsText():
+		return column.Truncate(hex.EncodeToString(sum)), nil
+	default:
+		return nil, fmt.Errorf("cannot tokenize column %s with type %s", column.Name, column.Type)
+	}
+}
+
+func (r *TokenizeRule) Apply(row *Row) error {
+	for _, columnName := range r.Columns {
+		column, ok := row.Table.Columns[columnName]
+		if !ok {
+			continue
+		}
+
+		datum, ok := row.Datum(column)
+		if !ok || datum.Kind() == types.KindNull {
+			continue
+		}
+		s, err := datum.ToString()
+		if err != nil {
+			return err
+		}
+		value, err := tokenValue(column, token(r.Key, s))
+		if err != nil {
+			return err
+		}
+		row.SetValue(column, value)
+	}
+	return nil
+}
+
+// readKey loads a secret from keyFile, relative to the config file, if one is
+// given, otherwise from the environment variable keyEnv.
+func readKey(config *Config, keyEnv string, keyFile *string) ([]byte, error) {
+	if keyFile != nil {
+		contents, err := os.ReadFile(config.Path(*keyFile))
+		if err != nil {
+			return nil, err
+		}
+		key := strings.TrimSpace(string(contents))
+		if len(key) == 0 {
+			return nil, fmt.Errorf("key file %s is empty", *keyFile)
+		}
+		return []byte(key), nil
+	}
+	key := os.Getenv(keyEnv)
+	if len(key) == 0 {
+		return nil, fmt.Errorf("no key found: set the %s environment variable or key_file", keyEnv)
+	}
+	return []byte(key), nil
+}
+
+func (r *TokenizeRule) CheckColumn(column *Column) error {
+	if !column.IsInteger() && !column.IsText() {
+		return fmt.Errorf("only integer and character columns can be tokenized")
+	}
+	return nil
+}
+
+func init() {
+	RegisterRule("tokenize", tokenizeRuleDefaultSpec, func(value cty.Value, block *hcl.Block, table *Table) (Rule, error) {
+		return NewTokenizeRule(value, table.Database.Config)
+	})
+}
+
+func NewTokenizeRule(value cty.Value, config *Config) (*TokenizeRule, error) {
+	rule := &TokenizeRule{}
+	err := gocty.FromCtyValue(value, &rule)
+	if err == nil {
+		rule.Key, err = readKey(config, rule.KeyEnv, rule.KeyFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
@@ -0,0 +1,46 @@
+policy "contact" {
+  rule "email" {
+    columns = [email]
+  }
+  rule "redact" {
+    columns = [notes, text]
+    mode    = "auto"
+  }
+}
+
+database "myapp_production" {
+  destination_database = "myapp_test"
+  columns "%phone%" {
+    rule "mask" {
+      pattern         = "[0-9]"
+      preserve_format = true
+      keep_last       = 4
+    }
+  }
+  table "users" {
+    policies = ["contact"]
+    rule "set" {
+      name = "User ${row.id}"
+    }
+    rule "mask" {
+      columns = [pin]
+    }
+  }
+  table "appointments" {
+    policies = ["contact"]
+    rule "time_extract" {
+      columns = [created_at]
+      keep    = "date"
+    }
+    rule "bucket" {
+      columns = [duration]
+      size    = 5
+    }
+  }
+  table "comments" {
+    policies = ["contact"]
+    where {
+      appointment_id = appointments.id
+    }
+  }
+}
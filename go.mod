@@ -3,6 +3,7 @@ module github.com/adwerx/dumpctl
 go 1.17
 
 require (
+	github.com/cespare/xxhash/v2 v2.1.2
 	github.com/doug-martin/goqu/v9 v9.18.0
 	github.com/go-sql-driver/mysql v1.6.0
 	github.com/hashicorp/hcl/v2 v2.12.0
@@ -11,6 +12,7 @@ require (
 	github.com/pingcap/tidb v1.1.0-beta.0.20221113031953-cf36a9ce2fe1
 	github.com/pingcap/tidb/parser v0.0.0-20221113031953-cf36a9ce2fe1
 	github.com/zclconf/go-cty v1.10.0
+	go.starlark.net v0.0.0-20230302034142-4b1e35fe2254
 )
 
 require (
@@ -20,7 +22,6 @@ require (
 	github.com/apparentlymart/go-textseg/v13 v13.0.0 // indirect
 	github.com/benbjohnson/clock v1.3.0 // indirect
 	github.com/beorn7/perks v1.0.1 // indirect
-	github.com/cespare/xxhash/v2 v2.1.2 // indirect
 	github.com/cockroachdb/errors v1.8.1 // indirect
 	github.com/cockroachdb/logtags v0.0.0-20190617123548-eb05cc24525f // indirect
 	github.com/cockroachdb/redact v1.0.8 // indirect
@@ -0,0 +1,11 @@
+database "0002-redact" {
+  table "people" {
+    rule "redact" {
+      columns = [name, score, kind, flags, bits, avatar, born_on, seen_at, updated_at, wakes_at, graduated, data]
+    }
+    rule "redact" {
+      columns = [phone, note]
+      mode    = "auto"
+    }
+  }
+}
@@ -0,0 +1,19 @@
+create table `people` (
+  `id` int not null primary key,
+  `name` varchar(64) not null,
+  `score` decimal(6,2) not null,
+  `kind` enum('member','admin') not null,
+  `flags` set('x','y') not null,
+  `bits` bit(4) not null,
+  `avatar` blob,
+  `born_on` date not null,
+  `seen_at` datetime not null,
+  `updated_at` timestamp not null default current_timestamp,
+  `wakes_at` time not null,
+  `graduated` year not null,
+  `data` json,
+  `phone` varchar(20),
+  `note` varchar(20) not null default 'none'
+);
+INSERT INTO `people` VALUES (1,'Ryder',98.50,'admin','x,y',b'0101',0x89504E47,'1990-05-17','2021-03-04 05:06:07','2021-03-04 05:06:07','07:30:00',2012,'{"a": 1}','555-1234','likes cats');
+INSERT INTO `people` VALUES (2,'Skye',12.25,'member','',b'1111',NULL,'1985-12-31','2020-01-01 00:00:00','1999-12-31 23:59:59','23:59:59',2001,'[1, 2]',NULL,'');
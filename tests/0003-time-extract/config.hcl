@@ -0,0 +1,20 @@
+database "0003-time-extract" {
+  table "appointments" {
+    rule "time_extract" {
+      columns = [born_on]
+      keep    = "year"
+    }
+    rule "time_extract" {
+      columns = [booked_at]
+      keep    = "year_month"
+    }
+    rule "time_extract" {
+      columns = [starts_at]
+      keep    = "date"
+    }
+    rule "time_extract" {
+      columns = [ends_at, updated_at]
+      keep    = "time"
+    }
+  }
+}
@@ -0,0 +1,10 @@
+create table `appointments` (
+  `id` int not null primary key,
+  `born_on` date not null,
+  `booked_at` datetime(3) not null,
+  `starts_at` datetime not null,
+  `ends_at` datetime not null,
+  `updated_at` timestamp not null default current_timestamp
+);
+INSERT INTO `appointments` VALUES (1,'1990-05-17','2021-03-04 05:06:07.123','2021-03-05 09:00:00','2021-03-05 09:30:00','2021-03-04 05:06:07');
+INSERT INTO `appointments` VALUES (2,'1985-12-31','2020-02-29 23:59:59.999','2020-02-29 12:00:00','2020-02-29 23:45:00','2038-01-19 03:14:07');
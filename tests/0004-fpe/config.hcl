@@ -0,0 +1,19 @@
+database "0004-fpe" {
+  table "accounts" {
+    rule "fpe" {
+      columns  = [ssn, number]
+      key_file = "fpe.key"
+    }
+    rule "fpe" {
+      columns  = [code]
+      key_file = "fpe.key"
+      alphabet = "alphanumeric"
+      short    = "skip"
+    }
+    rule "fpe" {
+      columns  = [profile]
+      key_file = "fpe.key"
+      paths    = ["$.phone"]
+    }
+  }
+}
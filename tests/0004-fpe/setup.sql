@@ -0,0 +1,11 @@
+create table `accounts` (
+  `id` int not null primary key,
+  `ssn` char(11) not null,
+  `number` bigint unsigned not null,
+  `code` varchar(12) not null,
+  `profile` json,
+  unique key `number` (`number`)
+);
+INSERT INTO `accounts` VALUES (1,'123-45-6789',4111111111111111,'ab-12','{"phone": "555-123-4567", "name": "Ryder"}');
+INSERT INTO `accounts` VALUES (2,'987-65-4321',4111111111111112,'X9Y8-Z7W6','{"phone": null}');
+INSERT INTO `accounts` VALUES (3,'---',4111111111111113,'a-1','{}');
@@ -0,0 +1,12 @@
+database "0005-email" {
+  table "users" {
+    rule "email" {
+      columns = [email]
+      local   = "keep"
+    }
+    rule "email" {
+      columns      = [backup_email]
+      keep_domains = ["mycompany.com"]
+    }
+  }
+}
@@ -0,0 +1,11 @@
+create table `users` (
+  `id` int not null primary key,
+  `email` varchar(20) not null,
+  `backup_email` varchar(255),
+  unique key `email` (`email`)
+);
+INSERT INTO `users` VALUES (1,'alice@corp.com','alice@mycompany.com');
+INSERT INTO `users` VALUES (2,'alice@home.org','alice@home.org');
+INSERT INTO `users` VALUES (3,'alice@school.edu',NULL);
+INSERT INTO `users` VALUES (4,'bartholomew@corp.com','');
+INSERT INTO `users` VALUES (5,'bartholomew@home.org','BART@HOME.ORG');
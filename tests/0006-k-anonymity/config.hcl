@@ -0,0 +1,16 @@
+database "0006-k-anonymity" {
+  table "patients" {
+    quasi_identifiers = [zip, birth_year, gender]
+    k                 = 2
+
+    generalize {
+      columns = [zip]
+      prefix  = 3
+      fill    = "*"
+    }
+    generalize {
+      columns    = [birth_year]
+      boundaries = [0, 1950, 1970, 1990]
+    }
+  }
+}
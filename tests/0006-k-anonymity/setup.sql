@@ -0,0 +1,13 @@
+create table `patients` (
+  `id` int not null primary key,
+  `zip` char(5) not null,
+  `birth_year` smallint not null,
+  `gender` enum('f','m','x') not null
+);
+INSERT INTO `patients` VALUES (1,'94110',1985,'f');
+INSERT INTO `patients` VALUES (2,'94110',1985,'f');
+INSERT INTO `patients` VALUES (3,'94112',1985,'m');
+INSERT INTO `patients` VALUES (4,'94117',1985,'m');
+INSERT INTO `patients` VALUES (5,'94103',1972,'f');
+INSERT INTO `patients` VALUES (6,'94107',1979,'f');
+INSERT INTO `patients` VALUES (7,'10001',1960,'x');
@@ -0,0 +1,17 @@
+policy "contact" {
+  rule "redact" {
+    columns = [email, "phone"]
+  }
+  rule "mask" {
+    columns = ["fax"]
+  }
+}
+
+database "0007-policies" {
+  table "users" {
+    policies = ["contact"]
+  }
+  table "companies" {
+    policies = ["contact"]
+  }
+}
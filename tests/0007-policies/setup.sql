@@ -0,0 +1,12 @@
+create table `users` (
+  `id` int not null primary key,
+  `email` varchar(255) not null
+);
+create table `companies` (
+  `id` int not null primary key,
+  `email` varchar(255),
+  `phone` varchar(20)
+);
+INSERT INTO `users` VALUES (1,'alice@corp.com');
+INSERT INTO `companies` VALUES (1,'info@corp.com','555-1234');
+INSERT INTO `companies` VALUES (2,NULL,NULL);